@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/ViBiOh/httputils/v4/pkg/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func fakeBridge(args []string) {
+	fs := flag.NewFlagSet("hue", flag.ExitOnError)
+
+	loggerConfig := logger.Flags(fs, "logger")
+	address := flags.New("", "fake-bridge").Name("Address").Default("127.0.0.1:8001").Label("Listen address of the fake bridge, for both HTTP and HTTPS").ToString(fs)
+	username := flags.New("", "fake-bridge").Name("Username").Default("fake-username").Label("Username accepted by the fake bridge").ToString(fs)
+
+	logger.Fatal(fs.Parse(args))
+
+	logger.Global(logger.New(loggerConfig))
+	defer logger.Close()
+
+	logger.Info("Fake bridge listening on %s, start hue with `-bridgeIP %s -username %s`", *address, *address, *username)
+
+	exitOnError(fakebridge.New(*username).ListenAndServe(*address))
+}
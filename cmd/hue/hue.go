@@ -23,6 +23,23 @@ import (
 var content embed.FS
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "plan":
+			plan(os.Args[2:])
+			return
+		case "validate":
+			validate(os.Args[2:])
+			return
+		case "pair":
+			pair(os.Args[2:])
+			return
+		case "fake-bridge":
+			fakeBridge(os.Args[2:])
+			return
+		}
+	}
+
 	fs := flag.NewFlagSet("hue", flag.ExitOnError)
 
 	appServerConfig := server.Flags(fs, "")
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/hue"
+)
+
+func pair(args []string) {
+	fs := flag.NewFlagSet("pair", flag.ExitOnError)
+
+	loggerConfig := logger.Flags(fs, "logger")
+	bridgeIP := flags.New("", "hue").Name("BridgeIP").Default("").Label("IP of Bridge, discovered if empty").ToString(fs)
+	discoveryURL := flags.New("", "hue").Name("DiscoveryURL").Default(bridge.DefaultDiscoveryURL).Label("Bridge discovery endpoint, used when mDNS and SSDP fail").ToString(fs)
+	timeout := flags.New("", "pair").Name("Timeout").Default("1m").Label("Duration to wait for the link button to be pressed").ToString(fs)
+	output := flags.New("", "pair").Name("Output").Default("").Label("Filename where username is written, printed if empty").ToString(fs)
+
+	logger.Fatal(fs.Parse(args))
+
+	logger.Global(logger.New(loggerConfig))
+	defer logger.Close()
+
+	waitDuration, err := time.ParseDuration(*timeout)
+	exitOnError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitDuration)
+	defer cancel()
+
+	ip := *bridgeIP
+	if len(ip) == 0 {
+		ip, err = bridge.Discover(ctx, *discoveryURL)
+		exitOnError(err)
+
+		fmt.Printf("Bridge found at %s\n", ip)
+	}
+
+	fmt.Printf("Press the link button of the bridge within %s...\n", waitDuration)
+
+	username, err := bridge.Pair(ctx, ip, hue.DeviceType())
+	exitOnError(err)
+
+	if len(*output) == 0 {
+		fmt.Printf("Paired! Use `-bridgeIP %s -username %s`\n", ip, username)
+		return
+	}
+
+	exitOnError(os.WriteFile(*output, []byte(username), 0o600))
+	fmt.Printf("Paired! Username written to %s\n", *output)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/hue"
+)
+
+func plan(args []string) {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+
+	loggerConfig := logger.Flags(fs, "logger")
+	hueConfig := hue.Flags(fs, "")
+
+	logger.Fatal(fs.Parse(args))
+
+	logger.Global(logger.New(loggerConfig))
+	defer logger.Close()
+
+	hueApp, err := hue.New(hueConfig, nil, nil)
+	logger.Fatal(err)
+
+	changes, err := hueApp.Plan(context.Background())
+	logger.Fatal(err)
+
+	if len(changes) == 0 {
+		fmt.Println("No changes. Bridge is up to date with configuration.")
+		return
+	}
+
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+
+	fmt.Printf("\nPlan: %d change(s)\n", len(changes))
+}
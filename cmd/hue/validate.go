@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/hue"
+)
+
+func validate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+
+	loggerConfig := logger.Flags(fs, "logger")
+	hueConfig := hue.Flags(fs, "")
+	offline := fs.Bool("offline", false, "Skip checks of groups and sensors against the bridge")
+
+	logger.Fatal(fs.Parse(args))
+
+	logger.Global(logger.New(loggerConfig))
+	defer logger.Close()
+
+	hueApp, err := hue.New(hueConfig, nil, nil)
+	exitOnError(err)
+
+	if !*offline {
+		exitOnError(hueApp.Validate(context.Background()))
+	}
+
+	fmt.Println("Configuration is valid.")
+}
+
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, err)
+	logger.Close()
+	os.Exit(1)
+}
@@ -0,0 +1,147 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+)
+
+const (
+	requestTimeout = time.Second * 15
+)
+
+// Client of a Hue bridge, with the v1 API
+type Client struct {
+	httpClient http.Client
+	url        string
+	username   string
+}
+
+// New creates a Client for given bridge's IP and username
+func New(bridgeIP, username string) Client {
+	return Client{
+		httpClient: http.Client{
+			Timeout: requestTimeout,
+		},
+		url:      fmt.Sprintf("http://%s/api/%s", bridgeIP, username),
+		username: username,
+	}
+}
+
+// NewSecure creates a Client for given bridge's IP and username, over HTTPS
+func NewSecure(bridgeIP, username string, tlsConfig *tls.Config) Client {
+	return Client{
+		httpClient: http.Client{
+			Timeout:   requestTimeout,
+			Transport: newTransport(tlsConfig),
+		},
+		url:      fmt.Sprintf("https://%s/api/%s", bridgeIP, username),
+		username: username,
+	}
+}
+
+// Username returns the username used for calling bridge
+func (c Client) Username() string {
+	return c.username
+}
+
+// IsZero checks if client is configured
+func (c Client) IsZero() bool {
+	return len(c.url) == 0
+}
+
+func (c Client) request() *request.Request {
+	return request.New().WithClient(c.httpClient)
+}
+
+func (c Client) get(ctx context.Context, path string, response interface{}) error {
+	resp, err := c.request().Get(c.url+path).Send(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	content, err := request.ReadBodyResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		if _, err := parseResults(content); err != nil {
+			return fmt.Errorf("unable to get `%s`: %w", path, err)
+		}
+	}
+
+	if err := json.Unmarshal(content, response); err != nil {
+		return fmt.Errorf("unable to read hue content: %s", err)
+	}
+
+	return nil
+}
+
+func (c Client) create(ctx context.Context, path string, payload interface{}) (string, error) {
+	resp, err := c.request().Post(c.url+path).JSON(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := request.ReadBodyResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	successes, err := parseResults(content)
+	if err != nil {
+		return "", fmt.Errorf("unable to create `%s`: %w", path, err)
+	}
+
+	var response struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(successes[0], &response); err != nil {
+		return "", fmt.Errorf("unable to read created id: %s", err)
+	}
+
+	return response.ID, nil
+}
+
+func (c Client) update(ctx context.Context, path string, payload interface{}) error {
+	resp, err := c.request().Put(c.url+path).JSON(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	content, err := request.ReadBodyResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if _, err := parseResults(content); err != nil {
+		return fmt.Errorf("unable to update `%s`: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c Client) remove(ctx context.Context, path string) error {
+	resp, err := c.request().Delete(c.url+path).Send(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	content, err := request.ReadBodyResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if _, err := parseResults(content); err != nil {
+		return fmt.Errorf("unable to remove `%s`: %w", path, err)
+	}
+
+	return nil
+}
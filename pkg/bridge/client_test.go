@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func TestClient(t *testing.T) {
+	fake, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	ctx := context.Background()
+	client := New(fakebridge.Address(server), "secret")
+
+	groups, err := client.ListGroups(ctx)
+	if err != nil {
+		t.Fatalf("ListGroups() error = %s", err)
+	}
+	if len(groups) != 3 || groups["1"].Name != "Living room" {
+		t.Errorf("ListGroups() = %+v, want 3 groups with `Living room`", groups)
+	}
+
+	on := true
+	bri := 128
+	if err := client.UpdateGroupAction(ctx, "1", State{On: &on, Bri: &bri}); err != nil {
+		t.Errorf("UpdateGroupAction() error = %s", err)
+	}
+
+	if light, err := client.GetLight(ctx, "2"); err != nil || !light.State.On || light.State.Bri != 128 {
+		t.Errorf("GetLight() = (%+v, %v), want light on at 128", light.State, err)
+	}
+
+	scenesCount := fake.Count("scenes")
+
+	scene := Scene{APIScene: APIScene{Name: "Relax", Lights: []string{"1", "2"}, Lightstates: map[string]State{"1": {On: &on, Bri: &bri}}}}
+	if err := client.CreateScene(ctx, &scene); err != nil || len(scene.ID) == 0 {
+		t.Errorf("CreateScene() = (`%s`, %v), want an ID", scene.ID, err)
+	}
+
+	if got, err := client.GetScene(ctx, scene.ID); err != nil || got.Owner != "secret" || *got.Lightstates["1"].Bri != 128 {
+		t.Errorf("GetScene() = (%+v, %v), want owned scene with lightstate", got, err)
+	}
+
+	rule := Rule{Name: "Tap 8.1", Conditions: []Condition{{Address: "/sensors/8/state/buttonevent", Operator: "dx"}}, Actions: []Action{{Address: "/groups/1/action", Method: http.MethodPut, Body: map[string]interface{}{"scene": scene.ID}}}}
+	if err := client.CreateRule(ctx, &rule); err != nil || rule.ID != "2" {
+		t.Errorf("CreateRule() = (`%s`, %v), want `2`", rule.ID, err)
+	}
+
+	if err := client.DeleteScene(ctx, scene.ID); err != nil || fake.Count("scenes") != scenesCount {
+		t.Errorf("DeleteScene() error = %v, with %d scenes left, want %d", err, fake.Count("scenes"), scenesCount)
+	}
+
+	tooBright := 300
+	if err := client.UpdateLightState(ctx, "1", State{On: &on, Bri: &tooBright}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("UpdateLightState() error = %v, want %s", err, ErrInvalidValue)
+	}
+
+	if _, err := client.GetGroup(ctx, "42"); !errors.Is(err, ErrNotAvailable) {
+		t.Errorf("GetGroup() error = %v, want %s", err, ErrNotAvailable)
+	}
+
+	if _, err := New(fakebridge.Address(server), "unknown").ListLights(ctx); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ListLights() error = %v, want %s", err, ErrUnauthorized)
+	}
+}
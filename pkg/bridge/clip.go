@@ -0,0 +1,202 @@
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+)
+
+const (
+	applicationKeyHeader = "hue-application-key"
+	maxEventSize         = 1 << 20
+)
+
+// ClipClient of a Hue bridge, with the CLIP v2 API
+type ClipClient struct {
+	httpClient   http.Client
+	streamClient http.Client
+	url          string
+	key          string
+}
+
+// Resource is a CLIP v2 resource, or a partial one when received from the event stream
+type Resource struct {
+	ID          string               `json:"id"`
+	IDV1        string               `json:"id_v1,omitempty"`
+	Type        string               `json:"type"`
+	Metadata    *ResourceMetadata    `json:"metadata,omitempty"`
+	On          *ResourceOn          `json:"on,omitempty"`
+	Dimming     *ResourceDimming     `json:"dimming,omitempty"`
+	Motion      *ResourceMotion      `json:"motion,omitempty"`
+	Temperature *ResourceTemperature `json:"temperature,omitempty"`
+	PowerState  *ResourcePowerState  `json:"power_state,omitempty"`
+}
+
+// ResourceMetadata description
+type ResourceMetadata struct {
+	Name string `json:"name"`
+}
+
+// ResourceOn description
+type ResourceOn struct {
+	On bool `json:"on"`
+}
+
+// ResourceDimming description, brightness is a percentage
+type ResourceDimming struct {
+	Brightness float64 `json:"brightness"`
+}
+
+// ResourceMotion description
+type ResourceMotion struct {
+	Motion      bool `json:"motion"`
+	MotionValid bool `json:"motion_valid"`
+}
+
+// ResourceTemperature description, in celsius
+type ResourceTemperature struct {
+	Temperature      float32 `json:"temperature"`
+	TemperatureValid bool    `json:"temperature_valid"`
+}
+
+// ResourcePowerState description
+type ResourcePowerState struct {
+	BatteryState string `json:"battery_state,omitempty"`
+	BatteryLevel uint   `json:"battery_level"`
+}
+
+// V1 returns kind and ID of the matching v1 resource, e.g. `lights` and `3` for `/lights/3`
+func (r Resource) V1() (string, string) {
+	parts := strings.Split(strings.Trim(r.IDV1, "/"), "/")
+	if len(parts) != 2 {
+		return "", ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// Event received from the event stream
+type Event struct {
+	CreationTime string     `json:"creationtime"`
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Data         []Resource `json:"data"`
+}
+
+type clipResponse struct {
+	Errors []struct {
+		Description string `json:"description"`
+	} `json:"errors"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewClip creates a ClipClient for given bridge's IP and application key, the v1 username
+func NewClip(bridgeIP, applicationKey string, tlsConfig *tls.Config) ClipClient {
+	transport := newTransport(tlsConfig)
+
+	return ClipClient{
+		httpClient: http.Client{
+			Timeout:   requestTimeout,
+			Transport: transport,
+		},
+		streamClient: http.Client{
+			Transport: transport,
+		},
+		url: fmt.Sprintf("https://%s", bridgeIP),
+		key: applicationKey,
+	}
+}
+
+// IsZero checks if client is configured
+func (c ClipClient) IsZero() bool {
+	return len(c.url) == 0
+}
+
+// ListResources of given type, e.g. `light`, `motion` or `temperature`
+func (c ClipClient) ListResources(ctx context.Context, resourceType string) ([]Resource, error) {
+	resp, err := request.New().WithClient(c.httpClient).Get(fmt.Sprintf("%s/clip/v2/resource/%s", c.url, resourceType)).Header(applicationKeyHeader, c.key).Send(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := request.ReadBodyResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var response clipResponse
+	if err := json.Unmarshal(content, &response); err != nil {
+		return nil, fmt.Errorf("unable to parse `%s` resources: %s", resourceType, err)
+	}
+
+	if len(response.Errors) != 0 {
+		messages := make([]string, len(response.Errors))
+		for index, item := range response.Errors {
+			messages[index] = item.Description
+		}
+
+		return nil, fmt.Errorf("unable to list `%s` resources: %s", resourceType, strings.Join(messages, ", "))
+	}
+
+	var resources []Resource
+	if err := json.Unmarshal(response.Data, &resources); err != nil {
+		return nil, fmt.Errorf("unable to parse `%s` resources: %s", resourceType, err)
+	}
+
+	return resources, nil
+}
+
+// Events listens to the event stream and calls onEvent for each event received, until context is done or stream is closed
+func (c ClipClient) Events(ctx context.Context, onEvent func(Event)) error {
+	resp, err := request.New().WithClient(c.streamClient).Get(fmt.Sprintf("%s/eventstream/clip/v2", c.url)).Header(applicationKeyHeader, c.key).Header("Accept", "text/event-stream").Send(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	return readEvents(resp.Body, onEvent)
+}
+
+func readEvents(reader io.Reader, onEvent func(Event)) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var data bytes.Buffer
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+			continue
+		}
+
+		if len(line) != 0 || data.Len() == 0 {
+			continue
+		}
+
+		var events []Event
+		if err := json.Unmarshal(data.Bytes(), &events); err != nil {
+			return fmt.Errorf("unable to parse event `%s`: %s", data.String(), err)
+		}
+
+		data.Reset()
+
+		for _, event := range events {
+			onEvent(event)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,112 @@
+package bridge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func TestClipClient(t *testing.T) {
+	_, server := fakebridge.NewTLSServer("secret")
+	defer server.Close()
+
+	tlsConfig, err := NewTLSConfig("", filepath.Join(t.TempDir(), "bridge.pin"))
+	if err != nil {
+		t.Fatalf("NewTLSConfig() error = %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	clip := NewClip(fakebridge.Address(server), "secret", tlsConfig)
+
+	lights, err := clip.ListResources(ctx, "light")
+	if err != nil || len(lights) != 4 || lights[0].IDV1 != "/lights/1" {
+		t.Fatalf("ListResources() = (%+v, %v), want 4 lights", lights, err)
+	}
+
+	events := make(chan Event, 1)
+	go func() {
+		_ = clip.Events(ctx, func(event Event) {
+			events <- event
+		})
+	}()
+
+	client := NewSecure(fakebridge.Address(server), "secret", tlsConfig)
+	on := true
+
+	for {
+		if err := client.UpdateLightState(ctx, "3", State{On: &on}); err != nil {
+			t.Fatalf("UpdateLightState() error = %s", err)
+		}
+
+		select {
+		case event := <-events:
+			if kind, id := event.Data[0].V1(); kind != "lights" || id != "3" || !event.Data[0].On.On {
+				t.Errorf("Events() = %+v, want light 3 on", event.Data[0])
+			}
+			return
+		case <-time.After(time.Millisecond * 100):
+		case <-ctx.Done():
+			t.Fatalf("Events() received nothing")
+		}
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	if _, err := NewTLSConfig("", ""); err == nil {
+		t.Errorf("NewTLSConfig() without CA nor pin succeeded, want error")
+	}
+
+	pinFile := filepath.Join(t.TempDir(), "bridge.pin")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tlsConfig, err := NewTLSConfig("", pinFile)
+	if err != nil {
+		t.Fatalf("NewTLSConfig() error = %s", err)
+	}
+
+	_, err = NewClip(strings.TrimPrefix(server.URL, "https://"), "secret", tlsConfig).ListResources(context.Background(), "light")
+	if err == nil || !strings.Contains(err.Error(), "not a bridge ID") {
+		t.Errorf("ListResources() error = %v, want certificate rejected", err)
+	}
+
+	_, first := fakebridge.NewTLSServer("secret")
+	defer first.Close()
+
+	if _, err = NewClip(fakebridge.Address(first), "secret", tlsConfig).ListResources(context.Background(), "light"); err != nil {
+		t.Fatalf("ListResources() error = %s", err)
+	}
+
+	if _, err := os.Stat(pinFile); err != nil {
+		t.Fatalf("pin not persisted: %s", err)
+	}
+
+	_, second := fakebridge.NewTLSServer("secret")
+	defer second.Close()
+
+	restarted, err := NewTLSConfig("", pinFile)
+	if err != nil {
+		t.Fatalf("NewTLSConfig() error = %s", err)
+	}
+
+	if _, err = NewClip(fakebridge.Address(first), "secret", restarted).ListResources(context.Background(), "light"); err != nil {
+		t.Errorf("ListResources() error = %s, want pinned certificate accepted after restart", err)
+	}
+
+	_, err = NewClip(fakebridge.Address(second), "secret", restarted).ListResources(context.Background(), "light")
+	if err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Errorf("ListResources() error = %v, want pinned certificate mismatch", err)
+	}
+}
@@ -0,0 +1,206 @@
+package bridge
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinMirek is the coldest color temperature accepted by the API
+	MinMirek = 153
+	// MaxMirek is the warmest color temperature accepted by the API
+	MaxMirek = 500
+)
+
+// XY is a point of the CIE 1931 color space
+type XY [2]float64
+
+// Gamut is the triangle of colors a light can reproduce, as red, green and blue corners
+type Gamut [3]XY
+
+var (
+	// GamutA of first generation LivingColors lights
+	GamutA = Gamut{{0.704, 0.296}, {0.2151, 0.7106}, {0.138, 0.08}}
+	// GamutB of first generation Hue bulbs
+	GamutB = Gamut{{0.675, 0.322}, {0.409, 0.518}, {0.167, 0.04}}
+	// GamutC of recent Hue bulbs
+	GamutC = Gamut{{0.6915, 0.3083}, {0.17, 0.7}, {0.1532, 0.0475}}
+)
+
+// Gamut of the light, from its capabilities or from its gamut type
+func (l Light) Gamut() Gamut {
+	if points := l.Capabilities.Control.ColorGamut; len(points) == 3 && len(points[0]) == 2 && len(points[1]) == 2 && len(points[2]) == 2 {
+		return Gamut{{points[0][0], points[0][1]}, {points[1][0], points[1][1]}, {points[2][0], points[2][1]}}
+	}
+
+	switch l.Capabilities.Control.ColorGamutType {
+	case "A":
+		return GamutA
+	case "B":
+		return GamutB
+	default:
+		return GamutC
+	}
+}
+
+// MirekRange of the light, API bounds when unknown
+func (l Light) MirekRange() (int, int) {
+	if ct := l.Capabilities.Control.CT; ct != nil {
+		return ct.Min, ct.Max
+	}
+
+	return MinMirek, MaxMirek
+}
+
+// Contains checks if point is inside the gamut
+func (g Gamut) Contains(point XY) bool {
+	side := func(a, b XY) float64 {
+		return (b[0]-a[0])*(point[1]-a[1]) - (b[1]-a[1])*(point[0]-a[0])
+	}
+
+	first, second, third := side(g[0], g[1]), side(g[1], g[2]), side(g[2], g[0])
+
+	return (first >= 0 && second >= 0 && third >= 0) || (first <= 0 && second <= 0 && third <= 0)
+}
+
+// Clamp returns the closest point of the gamut
+func (g Gamut) Clamp(point XY) XY {
+	if g.Contains(point) {
+		return point
+	}
+
+	closest := closestOnSegment(g[0], g[1], point)
+	for _, candidate := range []XY{closestOnSegment(g[1], g[2], point), closestOnSegment(g[2], g[0], point)} {
+		if distance(candidate, point) < distance(closest, point) {
+			closest = candidate
+		}
+	}
+
+	return XY{round4(closest[0]), round4(closest[1])}
+}
+
+func closestOnSegment(a, b, point XY) XY {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+
+	ratio := ((point[0]-a[0])*dx + (point[1]-a[1])*dy) / (dx*dx + dy*dy)
+	ratio = math.Max(0, math.Min(1, ratio))
+
+	return XY{a[0] + ratio*dx, a[1] + ratio*dy}
+}
+
+func distance(a, b XY) float64 {
+	return math.Hypot(a[0]-b[0], a[1]-b[1])
+}
+
+func round4(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+// ParseHexColor parses `#rrggbb` or `rrggbb` into RGB components
+func ParseHexColor(value string) (uint8, uint8, uint8, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, fmt.Errorf("malformed color `%s`, e.g. `#ff8800`", value)
+	}
+
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed color `%s`, e.g. `#ff8800`", value)
+	}
+
+	return uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), nil
+}
+
+// RGBToXY converts a sRGB color to its CIE xy coordinates, brightness is ignored
+func RGBToXY(red, green, blue uint8) XY {
+	r, g, b := gammaCorrection(red), gammaCorrection(green), gammaCorrection(blue)
+
+	x := r*0.664511 + g*0.154324 + b*0.162028
+	y := r*0.283881 + g*0.668433 + b*0.047685
+	z := r*0.000088 + g*0.072310 + b*0.986039
+
+	sum := x + y + z
+	if sum == 0 {
+		return XY{0.3127, 0.329}
+	}
+
+	return XY{round4(x / sum), round4(y / sum)}
+}
+
+func gammaCorrection(component uint8) float64 {
+	value := float64(component) / 255
+
+	if value > 0.04045 {
+		return math.Pow((value+0.055)/1.055, 2.4)
+	}
+
+	return value / 12.92
+}
+
+// XYToMirek approximates color temperature of a xy point, with McCamy's formula. Points far from white are bound to the warmest or coldest value
+func XYToMirek(point XY) int {
+	if point[1] <= 0.1858 {
+		return MinMirek
+	}
+
+	n := (point[0] - 0.332) / (0.1858 - point[1])
+	if n < -0.856 {
+		return MaxMirek
+	}
+
+	mirek := KelvinToMirek(int(449*n*n*n + 3525*n*n + 6823.3*n + 5520.33))
+
+	switch {
+	case mirek < MinMirek:
+		return MinMirek
+	case mirek > MaxMirek:
+		return MaxMirek
+	default:
+		return mirek
+	}
+}
+
+// KelvinToMirek converts a color temperature in kelvin to mirek
+func KelvinToMirek(kelvin int) int {
+	if kelvin <= 0 {
+		return MaxMirek
+	}
+
+	return int(math.Round(1000000 / float64(kelvin)))
+}
+
+// XYToRGB converts CIE xy coordinates to a sRGB color at full brightness
+func XYToRGB(point XY) (uint8, uint8, uint8) {
+	if point[1] == 0 {
+		return 255, 255, 255
+	}
+
+	x, y, z := point[0]/point[1], 1.0, (1-point[0]-point[1])/point[1]
+
+	r := x*1.656492 - y*0.354851 - z*0.255038
+	g := -x*0.707196 + y*1.655397 + z*0.036152
+	b := x*0.051713 - y*0.121364 + z*1.011530
+
+	max := math.Max(r, math.Max(g, b))
+	if max <= 0 {
+		return 0, 0, 0
+	}
+
+	return reverseGamma(r / max), reverseGamma(g / max), reverseGamma(b / max)
+}
+
+func reverseGamma(value float64) uint8 {
+	if value <= 0 {
+		return 0
+	}
+
+	if value <= 0.0031308 {
+		value *= 12.92
+	} else {
+		value = 1.055*math.Pow(value, 1/2.4) - 0.055
+	}
+
+	return uint8(math.Round(math.Min(1, value) * 255))
+}
@@ -0,0 +1,144 @@
+package bridge
+
+import (
+	"testing"
+)
+
+func TestRGBToXY(t *testing.T) {
+	type args struct {
+		red   uint8
+		green uint8
+		blue  uint8
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      XY
+	}{
+		{
+			"white",
+			args{255, 255, 255},
+			XY{0.3227, 0.329},
+		},
+		{
+			"red",
+			args{255, 0, 0},
+			XY{0.7006, 0.2993},
+		},
+		{
+			"black",
+			args{0, 0, 0},
+			XY{0.3127, 0.329},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := RGBToXY(tc.args.red, tc.args.green, tc.args.blue); got != tc.want {
+				t.Errorf("RGBToXY() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClamp(t *testing.T) {
+	type args struct {
+		gamut Gamut
+		point XY
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      XY
+	}{
+		{
+			"inside",
+			args{GamutC, XY{0.4573, 0.41}},
+			XY{0.4573, 0.41},
+		},
+		{
+			"outside red corner",
+			args{GamutC, XY{0.7006, 0.2993}},
+			XY{0.6915, 0.3083},
+		},
+		{
+			"outside edge",
+			args{GamutB, XY{0.5, 0.1}},
+			XY{0.447, 0.1954},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := tc.args.gamut.Clamp(tc.args.point); got != tc.want {
+				t.Errorf("Clamp() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestXYToMirek(t *testing.T) {
+	var cases = []struct {
+		intention string
+		point     XY
+		want      int
+	}{
+		{
+			"daylight",
+			XY{0.3127, 0.329},
+			154,
+		},
+		{
+			"warm white",
+			XY{0.4573, 0.41},
+			366,
+		},
+		{
+			"red",
+			XY{0.7006, 0.2993},
+			MaxMirek,
+		},
+		{
+			"blue",
+			XY{0.1532, 0.0475},
+			MinMirek,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := XYToMirek(tc.point); got != tc.want {
+				t.Errorf("XYToMirek() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestXYToRGB(t *testing.T) {
+	var cases = []struct {
+		intention string
+		point     XY
+		want      [3]uint8
+	}{
+		{
+			"red",
+			RGBToXY(255, 0, 0),
+			[3]uint8{255, 0, 0},
+		},
+		{
+			"orange",
+			RGBToXY(255, 136, 0),
+			[3]uint8{255, 136, 0},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if red, green, blue := XYToRGB(tc.point); [3]uint8{red, green, blue} != tc.want {
+				t.Errorf("XYToRGB() = %v, want %v", [3]uint8{red, green, blue}, tc.want)
+			}
+		})
+	}
+}
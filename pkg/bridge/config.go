@@ -0,0 +1,11 @@
+package bridge
+
+import (
+	"context"
+)
+
+// GetConfig of bridge
+func (c Client) GetConfig(ctx context.Context) (Config, error) {
+	var response Config
+	return response, c.get(ctx, "/config", &response)
+}
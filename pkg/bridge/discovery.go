@@ -0,0 +1,139 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+)
+
+const (
+	// DefaultDiscoveryURL is the Signify discovery endpoint
+	DefaultDiscoveryURL = "https://discovery.meethue.com"
+
+	multicastTimeout = time.Second * 3
+
+	ssdpAddress = "239.255.255.250:1900"
+	mdnsAddress = "224.0.0.251:5353"
+)
+
+var (
+	// ErrNotFound occurs when no bridge has been discovered
+	ErrNotFound = errors.New("no bridge found")
+
+	ssdpQuery = []byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 2\r\nST: ssdp:all\r\n\r\n")
+
+	// mDNS query of PTR record for _hue._tcp.local, with unicast response bit set
+	mdnsQuery = []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x04, '_', 'h', 'u', 'e',
+		0x04, '_', 't', 'c', 'p',
+		0x05, 'l', 'o', 'c', 'a', 'l',
+		0x00,
+		0x00, 0x0c,
+		0x80, 0x01,
+	}
+)
+
+type discoveredBridge struct {
+	ID                string `json:"id"`
+	InternalIPAddress string `json:"internalipaddress"`
+}
+
+// Discover finds a bridge on the local network by mDNS, then SSDP, then by calling the discovery endpoint, if provided
+func Discover(ctx context.Context, discoveryURL string) (string, error) {
+	if ip, err := multicastDiscover(ctx, mdnsAddress, mdnsQuery, []byte("_hue")); err == nil {
+		return ip, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		logger.Warn("unable to discover bridge with mDNS: %s", err)
+	}
+
+	if ip, err := multicastDiscover(ctx, ssdpAddress, ssdpQuery, []byte("IpBridge")); err == nil {
+		return ip, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		logger.Warn("unable to discover bridge with SSDP: %s", err)
+	}
+
+	if len(discoveryURL) == 0 {
+		return "", ErrNotFound
+	}
+
+	return EndpointDiscover(ctx, discoveryURL)
+}
+
+// EndpointDiscover finds a bridge by calling given discovery endpoint
+func EndpointDiscover(ctx context.Context, discoveryURL string) (string, error) {
+	resp, err := request.New().Get(discoveryURL).Send(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to call discovery endpoint: %s", err)
+	}
+
+	var bridges []discoveredBridge
+	if err := httpjson.Read(resp, &bridges); err != nil {
+		return "", fmt.Errorf("unable to read discovery response: %s", err)
+	}
+
+	for _, bridge := range bridges {
+		if len(bridge.InternalIPAddress) != 0 {
+			return bridge.InternalIPAddress, nil
+		}
+	}
+
+	return "", ErrNotFound
+}
+
+func multicastDiscover(ctx context.Context, address string, query, marker []byte) (string, error) {
+	destination, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logger.Error("unable to close discovery connection: %s", err)
+		}
+	}()
+
+	deadline := time.Now().Add(multicastTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return "", err
+	}
+
+	if _, err := conn.WriteToUDP(query, destination); err != nil {
+		return "", err
+	}
+
+	buffer := make([]byte, 2048)
+
+	for {
+		size, sender, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return "", ErrNotFound
+			}
+
+			return "", err
+		}
+
+		if bytes.Contains(buffer[:size], marker) {
+			return strings.TrimSpace(sender.IP.String()), nil
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	errorUnauthorizedUser       = 1
+	errorResourceNotAvailable   = 3
+	errorParameterNotAvailable  = 6
+	errorInvalidValue           = 7
+	errorParameterNotModifiable = 8
+	errorLinkButtonNotPressed   = 101
+	errorDeviceOff              = 201
+	errorInternal               = 901
+)
+
+var (
+	// ErrUnauthorized occurs when username is not known by the bridge
+	ErrUnauthorized = errors.New("unauthorized user")
+
+	// ErrNotAvailable occurs when requested resource or parameter doesn't exist
+	ErrNotAvailable = errors.New("resource not available")
+
+	// ErrInvalidValue occurs when a parameter is rejected by the bridge, including when the device is off
+	ErrInvalidValue = errors.New("invalid value")
+
+	// ErrInternal occurs when the bridge fails by itself
+	ErrInternal = errors.New("bridge internal error")
+)
+
+// APIError describes an error returned by the bridge
+type APIError struct {
+	Address     string `json:"address"`
+	Description string `json:"description"`
+	Type        int    `json:"type"`
+}
+
+func (e APIError) Error() string {
+	if len(e.Address) == 0 {
+		return fmt.Sprintf("%s (type %d)", e.Description, e.Type)
+	}
+
+	return fmt.Sprintf("%s: %s (type %d)", e.Address, e.Description, e.Type)
+}
+
+// Is checks if error is of given kind, with the sentinel errors of the package
+func (e APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Type == errorUnauthorizedUser
+	case ErrNotAvailable:
+		return e.Type == errorResourceNotAvailable || e.Type == errorParameterNotAvailable
+	case ErrInvalidValue:
+		return e.Type == errorInvalidValue || e.Type == errorParameterNotModifiable || e.Type == errorDeviceOff
+	case ErrInternal:
+		return e.Type == errorInternal
+	default:
+		return false
+	}
+}
+
+// APIErrors is the list of errors returned by the bridge for a single request
+type APIErrors []APIError
+
+func (e APIErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = err.Error()
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// Is checks if one of the errors is of given kind
+func (e APIErrors) Is(target error) bool {
+	for _, err := range e {
+		if err.Is(target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type result struct {
+	Success json.RawMessage `json:"success"`
+	Error   *APIError       `json:"error"`
+}
+
+func parseResults(content []byte) ([]json.RawMessage, error) {
+	var results []result
+	if err := json.Unmarshal(content, &results); err != nil {
+		return nil, fmt.Errorf("unable to parse bridge response `%s`: %s", content, err)
+	}
+
+	var successes []json.RawMessage
+	var errs APIErrors
+
+	for _, item := range results {
+		if item.Error != nil {
+			errs = append(errs, *item.Error)
+		} else if len(item.Success) != 0 {
+			successes = append(successes, item.Success)
+		}
+	}
+
+	if len(errs) > 0 {
+		return successes, errs
+	}
+
+	if len(successes) == 0 {
+		return nil, fmt.Errorf("no success in bridge response `%s`", content)
+	}
+
+	return successes, nil
+}
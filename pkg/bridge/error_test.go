@@ -0,0 +1,89 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseResults(t *testing.T) {
+	type args struct {
+		content string
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      int
+		wantErr   error
+	}{
+		{
+			"success",
+			args{
+				content: `[{"success":{"/groups/1/action/on":true}},{"success":{"/groups/1/action/bri":254}}]`,
+			},
+			2,
+			nil,
+		},
+		{
+			"partial success",
+			args{
+				content: `[{"success":{"/groups/1/action/on":true}},{"error":{"type":7,"address":"/groups/1/action/bri","description":"invalid value, 300, for parameter, bri"}}]`,
+			},
+			1,
+			ErrInvalidValue,
+		},
+		{
+			"device off",
+			args{
+				content: `[{"error":{"type":201,"address":"/lights/1/state/bri","description":"parameter, bri, is not modifiable. Device is set to off."}}]`,
+			},
+			0,
+			ErrInvalidValue,
+		},
+		{
+			"unauthorized",
+			args{
+				content: `[{"error":{"type":1,"address":"/","description":"unauthorized user"}}]`,
+			},
+			0,
+			ErrUnauthorized,
+		},
+		{
+			"not available",
+			args{
+				content: `[{"error":{"type":3,"address":"/groups/42","description":"resource, /groups/42, not available"}}]`,
+			},
+			0,
+			ErrNotAvailable,
+		},
+		{
+			"internal",
+			args{
+				content: `[{"error":{"type":901,"address":"/scenes","description":"Internal error, 404"}}]`,
+			},
+			0,
+			ErrInternal,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got, gotErr := parseResults([]byte(tc.args.content))
+
+			failed := false
+
+			if len(got) != tc.want {
+				failed = true
+			} else if tc.wantErr == nil && gotErr != nil {
+				failed = true
+			} else if tc.wantErr != nil && !errors.Is(fmt.Errorf("wrapped: %w", gotErr), tc.wantErr) {
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("parseResults() = (%d, `%s`), want (%d, `%s`)", len(got), gotErr, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
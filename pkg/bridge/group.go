@@ -0,0 +1,23 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListGroups of bridge, by ID
+func (c Client) ListGroups(ctx context.Context) (map[string]Group, error) {
+	var response map[string]Group
+	return response, c.get(ctx, "/groups", &response)
+}
+
+// GetGroup by ID
+func (c Client) GetGroup(ctx context.Context, id string) (Group, error) {
+	var response Group
+	return response, c.get(ctx, fmt.Sprintf("/groups/%s", id), &response)
+}
+
+// UpdateGroupAction applies given state or scene recall to all lights of the group
+func (c Client) UpdateGroupAction(ctx context.Context, id string, action interface{}) error {
+	return c.update(ctx, fmt.Sprintf("/groups/%s/action", id), action)
+}
@@ -0,0 +1,43 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ListLights of bridge, by ID
+func (c Client) ListLights(ctx context.Context) (map[string]Light, error) {
+	var response map[string]Light
+	return response, c.get(ctx, "/lights", &response)
+}
+
+// GetLight by ID
+func (c Client) GetLight(ctx context.Context, id string) (Light, error) {
+	var response Light
+	if err := c.get(ctx, fmt.Sprintf("/lights/%s", id), &response); err != nil {
+		return response, fmt.Errorf("unable to get light: %s", err)
+	}
+
+	return response, nil
+}
+
+// UpdateLightState applies given state to the light
+func (c Client) UpdateLightState(ctx context.Context, id string, state State) error {
+	return c.update(ctx, fmt.Sprintf("/lights/%s/state", id), state)
+}
+
+// IsDimmable checks if brightness of the light can be set
+func (l Light) IsDimmable() bool {
+	return !strings.HasPrefix(l.Type, "On/Off")
+}
+
+// HasCT checks if color temperature of the light can be set
+func (l Light) HasCT() bool {
+	return l.Capabilities.Control.CT != nil
+}
+
+// HasColor checks if color of the light can be set
+func (l Light) HasColor() bool {
+	return len(l.Capabilities.Control.ColorGamutType) != 0
+}
@@ -0,0 +1,269 @@
+package bridge
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var (
+	groupFinder = regexp.MustCompile(`(?mi)groups/(.*?)/`)
+)
+
+// Config description of bridge
+type Config struct {
+	Name          string `json:"name,omitempty"`
+	BridgeID      string `json:"bridgeid,omitempty"`
+	ModelID       string `json:"modelid,omitempty"`
+	APIVersion    string `json:"apiversion,omitempty"`
+	SWVersion     string `json:"swversion,omitempty"`
+	MAC           string `json:"mac,omitempty"`
+	IPAddress     string `json:"ipaddress,omitempty"`
+	Timezone      string `json:"timezone,omitempty"`
+	Localtime     string `json:"localtime,omitempty"`
+	UTC           string `json:"UTC,omitempty"`
+	ZigbeeChannel int    `json:"zigbeechannel,omitempty"`
+	LinkButton    bool   `json:"linkbutton,omitempty"`
+}
+
+// Group description
+type Group struct {
+	Name    string     `json:"name,omitempty"`
+	Type    string     `json:"type,omitempty"`
+	Class   string     `json:"class,omitempty"`
+	Lights  []string   `json:"lights,omitempty"`
+	Sensors []string   `json:"sensors,omitempty"`
+	Action  LightState `json:"action,omitempty"`
+	State   GroupState `json:"state,omitempty"`
+	Recycle bool       `json:"recycle,omitempty"`
+}
+
+// GroupState description
+type GroupState struct {
+	AllOn bool `json:"all_on"`
+	AnyOn bool `json:"any_on"`
+}
+
+// Light description
+type Light struct {
+	Name             string            `json:"name,omitempty"`
+	Type             string            `json:"type,omitempty"`
+	ModelID          string            `json:"modelid,omitempty"`
+	ManufacturerName string            `json:"manufacturername,omitempty"`
+	ProductName      string            `json:"productname,omitempty"`
+	UniqueID         string            `json:"uniqueid,omitempty"`
+	SWVersion        string            `json:"swversion,omitempty"`
+	State            LightState        `json:"state,omitempty"`
+	Capabilities     LightCapabilities `json:"capabilities,omitempty"`
+}
+
+// LightState description of the current state of a light
+type LightState struct {
+	Effect    string    `json:"effect,omitempty"`
+	Alert     string    `json:"alert,omitempty"`
+	ColorMode string    `json:"colormode,omitempty"`
+	Mode      string    `json:"mode,omitempty"`
+	XY        []float64 `json:"xy,omitempty"`
+	Bri       int       `json:"bri,omitempty"`
+	Hue       int       `json:"hue,omitempty"`
+	Sat       int       `json:"sat,omitempty"`
+	Ct        int       `json:"ct,omitempty"`
+	On        bool      `json:"on,omitempty"`
+	Reachable bool      `json:"reachable,omitempty"`
+}
+
+// LightCapabilities description
+type LightCapabilities struct {
+	Control LightControl `json:"control,omitempty"`
+}
+
+// LightControl description of what a light can do
+type LightControl struct {
+	ColorGamutType string        `json:"colorgamuttype,omitempty"`
+	ColorGamut     [][]float64   `json:"colorgamut,omitempty"`
+	CT             *LightRangeCT `json:"ct,omitempty"`
+	MinDimLevel    int           `json:"mindimlevel,omitempty"`
+	MaxLumen       int           `json:"maxlumen,omitempty"`
+}
+
+// LightRangeCT description of color temperature range, in mirek
+type LightRangeCT struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// State describes a state to apply to lights, groups or scenes. Nil fields are left unchanged
+type State struct {
+	On             *bool     `json:"on,omitempty"`
+	Bri            *int      `json:"bri,omitempty"`
+	Ct             *int      `json:"ct,omitempty"`
+	Hue            *int      `json:"hue,omitempty"`
+	Sat            *int      `json:"sat,omitempty"`
+	TransitionTime *int      `json:"transitiontime,omitempty"`
+	BriInc         *int      `json:"bri_inc,omitempty"`
+	CtInc          *int      `json:"ct_inc,omitempty"`
+	Effect         string    `json:"effect,omitempty"`
+	XY             []float64 `json:"xy,omitempty"`
+}
+
+// Body converts State to an untyped body, as used by rules and schedules actions
+func (s State) Body() map[string]interface{} {
+	body := make(map[string]interface{})
+
+	if s.On != nil {
+		body["on"] = *s.On
+	}
+	if s.Bri != nil {
+		body["bri"] = *s.Bri
+	}
+	if s.Ct != nil {
+		body["ct"] = *s.Ct
+	}
+	if s.Hue != nil {
+		body["hue"] = *s.Hue
+	}
+	if s.Sat != nil {
+		body["sat"] = *s.Sat
+	}
+	if s.TransitionTime != nil {
+		body["transitiontime"] = *s.TransitionTime
+	}
+	if s.BriInc != nil {
+		body["bri_inc"] = *s.BriInc
+	}
+	if s.CtInc != nil {
+		body["ct_inc"] = *s.CtInc
+	}
+	if len(s.Effect) != 0 {
+		body["effect"] = s.Effect
+	}
+	if len(s.XY) != 0 {
+		body["xy"] = s.XY
+	}
+
+	return body
+}
+
+func (s State) String() string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%v|%s", formatBool(s.On), formatInt(s.TransitionTime), formatInt(s.Sat), formatInt(s.Bri), formatInt(s.Ct), formatInt(s.Hue), s.XY, s.Effect)
+}
+
+func formatBool(value *bool) string {
+	if value == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("%t", *value)
+}
+
+func formatInt(value *int) string {
+	if value == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("%d", *value)
+}
+
+// APIScene describe scene as from Hue API
+type APIScene struct {
+	Lightstates map[string]State `json:"lightstates,omitempty"`
+	AppData     *SceneAppData    `json:"appdata,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Type        string           `json:"type,omitempty"`
+	Group       string           `json:"group,omitempty"`
+	Owner       string           `json:"owner,omitempty"`
+	Lights      []string         `json:"lights,omitempty"`
+	Recycle     bool             `json:"recycle"`
+}
+
+// SceneAppData description of data attached to scene by application
+type SceneAppData struct {
+	Data    string `json:"data,omitempty"`
+	Version int    `json:"version,omitempty"`
+}
+
+// Scene description
+type Scene struct {
+	ID string `json:"id,omitempty"`
+	APIScene
+}
+
+// Rule description
+type Rule struct {
+	ID             string      `json:"-"`
+	Status         string      `json:"status,omitempty"`
+	Name           string      `json:"name,omitempty"`
+	Owner          string      `json:"owner,omitempty"`
+	LastTriggered  string      `json:"lasttriggered,omitempty"`
+	Created        string      `json:"created,omitempty"`
+	Actions        []Action    `json:"actions,omitempty"`
+	Conditions     []Condition `json:"conditions,omitempty"`
+	TimesTriggered int         `json:"timestriggered,omitempty"`
+}
+
+// ResourceLink description, grouping resources of the bridge
+type ResourceLink struct {
+	ID          string   `json:"-"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	Links       []string `json:"links,omitempty"`
+	ClassID     int      `json:"classid,omitempty"`
+	Recycle     bool     `json:"recycle,omitempty"`
+}
+
+// Sensor description
+type Sensor struct {
+	ID               string       `json:"-"`
+	Name             string       `json:"name,omitempty"`
+	Type             string       `json:"type,omitempty"`
+	ModelID          string       `json:"modelid,omitempty"`
+	ManufacturerName string       `json:"manufacturername,omitempty"`
+	UniqueID         string       `json:"uniqueid,omitempty"`
+	SWVersion        string       `json:"swversion,omitempty"`
+	State            SensorState  `json:"state,omitempty"`
+	Config           SensorConfig `json:"config,omitempty"`
+}
+
+// SensorState description
+type SensorState struct {
+	LastUpdated string  `json:"lastupdated,omitempty"`
+	Temperature float32 `json:"temperature,omitempty"`
+	ButtonEvent int     `json:"buttonevent,omitempty"`
+	LightLevel  int     `json:"lightlevel,omitempty"`
+	Status      int     `json:"status,omitempty"`
+	Presence    bool    `json:"presence"`
+	Dark        bool    `json:"dark,omitempty"`
+	Daylight    bool    `json:"daylight,omitempty"`
+}
+
+// SensorConfig description
+type SensorConfig struct {
+	Battery       uint `json:"battery,omitempty"`
+	On            bool `json:"on"`
+	LedIndication bool `json:"ledindication"`
+}
+
+// Action description
+type Action struct {
+	Address string                 `json:"address,omitempty"`
+	Body    map[string]interface{} `json:"body,omitempty"`
+	Method  string                 `json:"method,omitempty"`
+}
+
+// GetGroup returns the group ID of the Action performed
+func (a Action) GetGroup() string {
+	matches := groupFinder.FindStringSubmatch(a.Address)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+
+	return ""
+}
+
+// Condition description
+type Condition struct {
+	Address  string `json:"address,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
@@ -0,0 +1,81 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+)
+
+var (
+	pairInterval = time.Second * 2
+)
+
+type pairResponse struct {
+	Success *struct {
+		Username string `json:"username"`
+	} `json:"success"`
+	Error *APIError `json:"error"`
+}
+
+// Pair creates a username on the bridge, waiting for the link button to be pressed until context is done
+func Pair(ctx context.Context, bridgeIP, deviceType string) (string, error) {
+	ticker := time.NewTicker(pairInterval)
+	defer ticker.Stop()
+
+	for {
+		username, err := requestUsername(ctx, bridgeIP, deviceType)
+		if err != nil && ctx.Err() != nil {
+			return "", fmt.Errorf("link button not pressed: %s", ctx.Err())
+		}
+
+		if err != nil || len(username) != 0 {
+			return username, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("link button not pressed: %s", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func requestUsername(ctx context.Context, bridgeIP, deviceType string) (string, error) {
+	resp, err := request.New().Post(fmt.Sprintf("http://%s/api", bridgeIP)).JSON(ctx, map[string]string{
+		"devicetype": deviceType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	content, err := request.ReadBodyResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var response []pairResponse
+	if err := json.Unmarshal(content, &response); err != nil {
+		return "", fmt.Errorf("unable to parse pairing response: %s", err)
+	}
+
+	if len(response) == 0 {
+		return "", fmt.Errorf("empty pairing response")
+	}
+
+	if response[0].Error != nil {
+		if response[0].Error.Type == errorLinkButtonNotPressed {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("unable to pair: %w", *response[0].Error)
+	}
+
+	if response[0].Success == nil || len(response[0].Success.Username) == 0 {
+		return "", fmt.Errorf("no username in pairing response: %s", content)
+	}
+
+	return response[0].Success.Username, nil
+}
@@ -0,0 +1,145 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakePairingBridge(pressedAfter int) *httptest.Server {
+	calls := 0
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		calls++
+
+		if calls <= pressedAfter {
+			fmt.Fprint(w, `[{"error":{"type":101,"address":"","description":"link button not pressed"}}]`)
+			return
+		}
+
+		fmt.Fprint(w, `[{"success":{"username":"83b7780291a6ceffbe0bd049104df"}}]`)
+	}))
+}
+
+func TestPair(t *testing.T) {
+	pairInterval = time.Millisecond * 10
+
+	type args struct {
+		pressedAfter int
+		timeout      time.Duration
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+		wantErr   string
+	}{
+		{
+			"already pressed",
+			args{
+				pressedAfter: 0,
+				timeout:      time.Second,
+			},
+			"83b7780291a6ceffbe0bd049104df",
+			"",
+		},
+		{
+			"pressed while waiting",
+			args{
+				pressedAfter: 3,
+				timeout:      time.Second,
+			},
+			"83b7780291a6ceffbe0bd049104df",
+			"",
+		},
+		{
+			"never pressed",
+			args{
+				pressedAfter: 1000,
+				timeout:      time.Millisecond * 50,
+			},
+			"",
+			"link button not pressed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			server := fakePairingBridge(tc.args.pressedAfter)
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.args.timeout)
+			defer cancel()
+
+			got, err := Pair(ctx, strings.TrimPrefix(server.URL, "http://"), "hue#test")
+
+			failed := false
+
+			if err == nil && len(tc.wantErr) != 0 {
+				failed = true
+			} else if err != nil && (len(tc.wantErr) == 0 || !strings.Contains(err.Error(), tc.wantErr)) {
+				failed = true
+			} else if got != tc.want {
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("Pair() = (`%s`, `%s`), want (`%s`, `%s`)", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEndpointDiscover(t *testing.T) {
+	type args struct {
+		payload string
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+		wantErr   error
+	}{
+		{
+			"found",
+			args{
+				payload: `[{"id":"001788fffe100491","internalipaddress":"192.168.1.10"}]`,
+			},
+			"192.168.1.10",
+			nil,
+		},
+		{
+			"empty",
+			args{
+				payload: `[]`,
+			},
+			"",
+			ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.args.payload)
+			}))
+			defer server.Close()
+
+			got, err := EndpointDiscover(context.Background(), server.URL)
+			if got != tc.want || err != tc.wantErr {
+				t.Errorf("EndpointDiscover() = (`%s`, `%s`), want (`%s`, `%s`)", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ListResourceLinks of bridge, by ID
+func (c Client) ListResourceLinks(ctx context.Context) (map[string]ResourceLink, error) {
+	var response map[string]ResourceLink
+	if err := c.get(ctx, "/resourcelinks", &response); err != nil {
+		return nil, err
+	}
+
+	for id, resourceLink := range response {
+		resourceLink.ID = id
+		response[id] = resourceLink
+	}
+
+	return response, nil
+}
+
+// CreateResourceLink creates given resource link then fills its ID
+func (c Client) CreateResourceLink(ctx context.Context, o *ResourceLink) error {
+	id, err := c.create(ctx, "/resourcelinks", o)
+	if err != nil {
+		return err
+	}
+
+	o.ID = id
+
+	return nil
+}
+
+// UpdateResourceLink updates given resource link
+func (c Client) UpdateResourceLink(ctx context.Context, o ResourceLink) error {
+	if o.ID == "" {
+		return errors.New("missing resource link ID to update")
+	}
+
+	return c.update(ctx, fmt.Sprintf("/resourcelinks/%s", o.ID), o)
+}
+
+// DeleteResourceLink by ID
+func (c Client) DeleteResourceLink(ctx context.Context, id string) error {
+	return c.remove(ctx, fmt.Sprintf("/resourcelinks/%s", id))
+}
@@ -0,0 +1,48 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ListRules of bridge, by ID
+func (c Client) ListRules(ctx context.Context) (map[string]Rule, error) {
+	var response map[string]Rule
+	if err := c.get(ctx, "/rules", &response); err != nil {
+		return nil, err
+	}
+
+	for id, rule := range response {
+		rule.ID = id
+		response[id] = rule
+	}
+
+	return response, nil
+}
+
+// CreateRule creates given rule then fills its ID
+func (c Client) CreateRule(ctx context.Context, o *Rule) error {
+	id, err := c.create(ctx, "/rules", o)
+	if err != nil {
+		return err
+	}
+
+	o.ID = id
+
+	return nil
+}
+
+// UpdateRule updates given rule
+func (c Client) UpdateRule(ctx context.Context, o Rule) error {
+	if o.ID == "" {
+		return errors.New("missing rule ID to update")
+	}
+
+	return c.update(ctx, fmt.Sprintf("/rules/%s", o.ID), o)
+}
+
+// DeleteRule by ID
+func (c Client) DeleteRule(ctx context.Context, id string) error {
+	return c.remove(ctx, fmt.Sprintf("/rules/%s", id))
+}
@@ -0,0 +1,93 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ListScenes of bridge, by ID. Lightstates are not filled, use GetScene to get them
+func (c Client) ListScenes(ctx context.Context) (map[string]Scene, error) {
+	var response map[string]Scene
+	if err := c.get(ctx, "/scenes", &response); err != nil {
+		return nil, err
+	}
+
+	for id, scene := range response {
+		scene.ID = id
+		response[id] = scene
+	}
+
+	return response, nil
+}
+
+// GetScene by ID, with lightstates
+func (c Client) GetScene(ctx context.Context, id string) (Scene, error) {
+	var response Scene
+	if err := c.get(ctx, fmt.Sprintf("/scenes/%s", id), &response); err != nil {
+		return response, err
+	}
+
+	response.ID = id
+
+	return response, nil
+}
+
+// CreateScene creates given scene, and its lightstates, then fills its ID
+func (c Client) CreateScene(ctx context.Context, o *Scene) error {
+	payload := o.APIScene
+	payload.Lightstates = nil
+
+	id, err := c.create(ctx, "/scenes", payload)
+	if err != nil {
+		return err
+	}
+
+	o.ID = id
+
+	for lightID, state := range o.Lightstates {
+		if err := c.UpdateSceneLightState(ctx, o.ID, lightID, state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateScene updates name, lights, appdata and lightstates of given scene
+func (c Client) UpdateScene(ctx context.Context, o Scene) error {
+	if o.ID == "" {
+		return errors.New("missing scene ID to update")
+	}
+
+	if err := c.update(ctx, fmt.Sprintf("/scenes/%s", o.ID), map[string]interface{}{
+		"name":    o.Name,
+		"lights":  o.Lights,
+		"appdata": o.AppData,
+	}); err != nil {
+		return err
+	}
+
+	for lightID, state := range o.Lightstates {
+		if err := c.UpdateSceneLightState(ctx, o.ID, lightID, state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RenameScene changes only the name of the scene, lights of group scenes can't be changed
+func (c Client) RenameScene(ctx context.Context, id, name string) error {
+	return c.update(ctx, fmt.Sprintf("/scenes/%s", id), map[string]string{"name": name})
+}
+
+// UpdateSceneLightState sets the state of a light in a scene
+func (c Client) UpdateSceneLightState(ctx context.Context, id, lightID string, state State) error {
+	return c.update(ctx, fmt.Sprintf("/scenes/%s/lightstates/%s", id, lightID), state)
+}
+
+// DeleteScene by ID
+func (c Client) DeleteScene(ctx context.Context, id string) error {
+	return c.remove(ctx, fmt.Sprintf("/scenes/%s", id))
+}
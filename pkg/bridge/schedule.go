@@ -0,0 +1,182 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	sunday = 1 << iota
+	saturday
+	friday
+	thursday
+	wednesday
+	tuesday
+	monday
+
+	weekday = monday | tuesday | wednesday | thursday | friday
+	weekend = saturday | sunday
+	alldays = weekday | weekend
+
+	// RecurrenceAllDays is the bitmask of recurring schedules for every day
+	RecurrenceAllDays = alldays
+)
+
+// Schedule description
+type Schedule struct {
+	ID string `json:"id,omitempty"`
+	APISchedule
+}
+
+// APISchedule describe schedule as from Hue API
+type APISchedule struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Localtime   string `json:"localtime,omitempty"`
+	Command     Action `json:"command,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
+var dayNames = []struct {
+	name  string
+	value int
+}{
+	{"mon", monday},
+	{"tue", tuesday},
+	{"wed", wednesday},
+	{"thu", thursday},
+	{"fri", friday},
+	{"sat", saturday},
+	{"sun", sunday},
+}
+
+// ParseRecurrence converts day names, from `mon` to `sun`, to the bitmask of recurring schedules
+func ParseRecurrence(days []string) (int, error) {
+	recurrence := 0
+
+	for _, day := range days {
+		found := false
+
+		for _, item := range dayNames {
+			if strings.EqualFold(day, item.name) {
+				recurrence |= item.value
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return 0, fmt.Errorf("unknown day `%s`", day)
+		}
+	}
+
+	if recurrence == 0 {
+		return 0, errors.New("at least one day is required")
+	}
+
+	return recurrence, nil
+}
+
+// RecurrenceDays converts the bitmask of recurring schedules to day names, from `mon` to `sun`
+func RecurrenceDays(recurrence int) []string {
+	var days []string
+
+	for _, item := range dayNames {
+		if recurrence&item.value != 0 {
+			days = append(days, item.name)
+		}
+	}
+
+	return days
+}
+
+func recurrenceStr(recurrence int) string {
+	if recurrence == alldays {
+		return "All days"
+	}
+	if recurrence == weekday {
+		return "Week days"
+	}
+	if recurrence == weekend {
+		return "Weekend"
+	}
+
+	days := make([]string, 0)
+
+	if recurrence&monday != 0 {
+		days = append(days, "Mon")
+	}
+	if recurrence&tuesday != 0 {
+		days = append(days, "Tue")
+	}
+	if recurrence&wednesday != 0 {
+		days = append(days, "Wed")
+	}
+	if recurrence&thursday != 0 {
+		days = append(days, "Thu")
+	}
+	if recurrence&friday != 0 {
+		days = append(days, "Fri")
+	}
+	if recurrence&saturday != 0 {
+		days = append(days, "Sat")
+	}
+	if recurrence&sunday != 0 {
+		days = append(days, "Sun")
+	}
+
+	return strings.Join(days, ", ")
+}
+
+// FormatLocalTime formats local time of schedules to human readable version
+func (s Schedule) FormatLocalTime() string {
+	pattern, err := ParseTimePattern(s.Localtime)
+	if err != nil {
+		return s.Localtime
+	}
+
+	return pattern.String()
+}
+
+// ListSchedules of bridge, by ID
+func (c Client) ListSchedules(ctx context.Context) (map[string]Schedule, error) {
+	var response map[string]Schedule
+	if err := c.get(ctx, "/schedules", &response); err != nil {
+		return nil, err
+	}
+
+	for id, schedule := range response {
+		schedule.ID = id
+		response[id] = schedule
+	}
+
+	return response, nil
+}
+
+// CreateSchedule creates given schedule then fills its ID
+func (c Client) CreateSchedule(ctx context.Context, o *Schedule) error {
+	id, err := c.create(ctx, "/schedules", o.APISchedule)
+	if err != nil {
+		return err
+	}
+
+	o.ID = id
+
+	return nil
+}
+
+// UpdateSchedule updates given schedule
+func (c Client) UpdateSchedule(ctx context.Context, o Schedule) error {
+	if o.ID == "" {
+		return errors.New("missing schedule ID to update")
+	}
+
+	return c.update(ctx, fmt.Sprintf("/schedules/%s", o.ID), o.APISchedule)
+}
+
+// DeleteSchedule by ID
+func (c Client) DeleteSchedule(ctx context.Context, id string) error {
+	return c.remove(ctx, fmt.Sprintf("/schedules/%s", id))
+}
@@ -0,0 +1,253 @@
+package bridge
+
+import (
+	"testing"
+)
+
+func TestFormatLocalTime(t *testing.T) {
+	type args struct {
+		instance Schedule
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+	}{
+		{
+			"no prefix",
+			args{
+				instance: Schedule{
+					ID: "",
+					APISchedule: APISchedule{
+						Localtime: "08:00:00",
+					},
+				},
+			},
+			"08:00:00",
+		},
+		{
+			"invalid number",
+			args{
+				instance: Schedule{
+					ID: "",
+					APISchedule: APISchedule{
+						Localtime: "WABC 08:00:00",
+					},
+				},
+			},
+			"WABC 08:00:00",
+		},
+		{
+			"all days",
+			args{
+				instance: Schedule{
+					ID: "",
+					APISchedule: APISchedule{
+						Localtime: "W127/T08:00:00",
+					},
+				},
+			},
+			"All days at 08:00:00",
+		},
+		{
+			"week days",
+			args{
+				instance: Schedule{
+					ID: "",
+					APISchedule: APISchedule{
+						Localtime: "W124/T10:00:00",
+					},
+				},
+			},
+			"Week days at 10:00:00",
+		},
+		{
+			"weekend",
+			args{
+				instance: Schedule{
+					ID: "",
+					APISchedule: APISchedule{
+						Localtime: "W003/T10:00:00",
+					},
+				},
+			},
+			"Weekend at 10:00:00",
+		},
+		{
+			"mon, wed, fri, sun",
+			args{
+				instance: Schedule{
+					ID: "",
+					APISchedule: APISchedule{
+						Localtime: "W085/T12:00:00",
+					},
+				},
+			},
+			"Mon, Wed, Fri, Sun at 12:00:00",
+		},
+		{
+			"tue, thu, sat",
+			args{
+				instance: Schedule{
+					ID: "",
+					APISchedule: APISchedule{
+						Localtime: "W042/T14:00:00",
+					},
+				},
+			},
+			"Tue, Thu, Sat at 14:00:00",
+		},
+		{
+			"absolute",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "2026-10-20T07:30:00",
+					},
+				},
+			},
+			"Tue 20 Oct 2026 at 07:30:00",
+		},
+		{
+			"timer",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "PT00:30:00",
+					},
+				},
+			},
+			"In 00:30:00",
+		},
+		{
+			"recurring timer",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "R/PT01:00:00",
+					},
+				},
+			},
+			"Every 01:00:00",
+		},
+		{
+			"limited recurring timer",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "R05/PT00:10:00",
+					},
+				},
+			},
+			"Every 00:10:00, 5 times",
+		},
+		{
+			"randomized",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "W124/T07:00:00A00:30:00",
+					},
+				},
+			},
+			"Week days at 07:00:00, randomized by up to 00:30:00",
+		},
+		{
+			"interval",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "W003/T10:00:00/T12:00:00",
+					},
+				},
+			},
+			"Weekend from 10:00:00 to 12:00:00",
+		},
+		{
+			"daily interval",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "T22:00:00/T06:00:00",
+					},
+				},
+			},
+			"All days from 22:00:00 to 06:00:00",
+		},
+		{
+			"randomized absolute",
+			args{
+				instance: Schedule{
+					APISchedule: APISchedule{
+						Localtime: "2026-10-20T07:30:00A00:10:00",
+					},
+				},
+			},
+			"Tue 20 Oct 2026 at 07:30:00, randomized by up to 00:10:00",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := tc.args.instance.FormatLocalTime(); got != tc.want {
+				t.Errorf("FormatLocalTime() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	type args struct {
+		days []string
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      int
+		wantErr   bool
+	}{
+		{
+			"week days",
+			args{
+				days: []string{"mon", "tue", "wed", "thu", "fri"},
+			},
+			weekday,
+			false,
+		},
+		{
+			"case insensitive",
+			args{
+				days: []string{"Sat", "SUN"},
+			},
+			weekend,
+			false,
+		},
+		{
+			"empty",
+			args{},
+			0,
+			true,
+		},
+		{
+			"unknown",
+			args{
+				days: []string{"monday"},
+			},
+			0,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got, err := ParseRecurrence(tc.args.days)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ParseRecurrence() error = %v, wantErr %t", err, tc.wantErr)
+			} else if got != tc.want {
+				t.Errorf("ParseRecurrence() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ListSensors of bridge, by ID
+func (c Client) ListSensors(ctx context.Context) (map[string]Sensor, error) {
+	var response map[string]Sensor
+	if err := c.get(ctx, "/sensors", &response); err != nil {
+		return nil, err
+	}
+
+	for id, sensor := range response {
+		sensor.ID = id
+		response[id] = sensor
+	}
+
+	return response, nil
+}
+
+// UpdateSensorConfig updates config of given sensor
+func (c Client) UpdateSensorConfig(ctx context.Context, o Sensor) error {
+	if o.ID == "" {
+		return errors.New("missing sensor ID to update")
+	}
+
+	return c.update(ctx, fmt.Sprintf("/sensors/%s/config", o.ID), o.Config)
+}
+
+// UpdateSensorState updates state of given sensor, only CLIP sensors accept it
+func (c Client) UpdateSensorState(ctx context.Context, id string, state interface{}) error {
+	return c.update(ctx, fmt.Sprintf("/sensors/%s/state", id), state)
+}
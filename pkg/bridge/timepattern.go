@@ -0,0 +1,248 @@
+package bridge
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PatternKind is the kind of a time pattern
+type PatternKind string
+
+const (
+	// KindAbsolute triggers once, at a given date and time
+	KindAbsolute PatternKind = "absolute"
+	// KindRecurring triggers on given days, at a given time
+	KindRecurring PatternKind = "recurring"
+	// KindInterval is active on given days, between two times
+	KindInterval PatternKind = "interval"
+	// KindTimer triggers once, after a given duration
+	KindTimer PatternKind = "timer"
+	// KindRecurringTimer triggers after each given duration, a given number of times or forever
+	KindRecurringTimer PatternKind = "recurringTimer"
+
+	dateTimeFormat = "2006-01-02T15:04:05"
+)
+
+var (
+	clockPattern = `(\d{2}):(\d{2}):(\d{2})`
+
+	absoluteRegex       = regexp.MustCompile(fmt.Sprintf(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2})(?:A%s)?$`, clockPattern))
+	recurringRegex      = regexp.MustCompile(fmt.Sprintf(`^W(\d{3})/T%[1]s(?:A%[1]s)?$`, clockPattern))
+	intervalRegex       = regexp.MustCompile(fmt.Sprintf(`^(?:W(\d{3})/)?T%[1]s/T%[1]s$`, clockPattern))
+	timerRegex          = regexp.MustCompile(fmt.Sprintf(`^PT%[1]s(?:A%[1]s)?$`, clockPattern))
+	recurringTimerRegex = regexp.MustCompile(fmt.Sprintf(`^R(\d{2})?/PT%[1]s(?:A%[1]s)?$`, clockPattern))
+)
+
+// TimePattern is a parsed time pattern of schedules
+type TimePattern struct {
+	Date   time.Time
+	Kind   PatternKind
+	Days   int
+	Time   time.Duration
+	End    time.Duration
+	Random time.Duration
+	Repeat int
+}
+
+// ParseTimePattern parses every time pattern of the v1 API
+func ParseTimePattern(value string) (TimePattern, error) {
+	var pattern TimePattern
+	var err error
+
+	switch {
+	case strings.HasPrefix(value, "W") && !intervalRegex.MatchString(value):
+		pattern.Kind = KindRecurring
+		err = pattern.parse(recurringRegex, value, &pattern.Days, &pattern.Time, &pattern.Random)
+
+	case strings.HasPrefix(value, "W"), strings.HasPrefix(value, "T"):
+		pattern.Kind = KindInterval
+		err = pattern.parse(intervalRegex, value, &pattern.Days, &pattern.Time, &pattern.End)
+
+	case strings.HasPrefix(value, "PT"):
+		pattern.Kind = KindTimer
+		err = pattern.parse(timerRegex, value, &pattern.Time, &pattern.Random)
+
+	case strings.HasPrefix(value, "R"):
+		pattern.Kind = KindRecurringTimer
+		err = pattern.parse(recurringTimerRegex, value, &pattern.Repeat, &pattern.Time, &pattern.Random)
+
+	default:
+		pattern.Kind = KindAbsolute
+		err = pattern.parse(absoluteRegex, value, &pattern.Date, &pattern.Random)
+	}
+
+	if err != nil {
+		return TimePattern{}, fmt.Errorf("malformed time pattern `%s`: %s", value, err)
+	}
+
+	return pattern, nil
+}
+
+// parse fills given fields, in order, from the groups of the regex: 1 group for an integer or a date, 3 for a clock
+func (p *TimePattern) parse(regex *regexp.Regexp, value string, fields ...interface{}) error {
+	matches := regex.FindStringSubmatch(value)
+	if matches == nil {
+		return fmt.Errorf("unknown format for %s", p.Kind)
+	}
+
+	groups := matches[1:]
+
+	for _, field := range fields {
+		switch output := field.(type) {
+		case *int:
+			if len(groups[0]) != 0 {
+				number, err := strconv.Atoi(groups[0])
+				if err != nil {
+					return err
+				}
+				*output = number
+			}
+			groups = groups[1:]
+
+		case *time.Time:
+			date, err := time.Parse(dateTimeFormat, groups[0])
+			if err != nil {
+				return err
+			}
+			*output = date
+			groups = groups[1:]
+
+		case *time.Duration:
+			if len(groups[0]) != 0 {
+				clock, err := parseClockGroups(groups[:3])
+				if err != nil {
+					return err
+				}
+				*output = clock
+			}
+			groups = groups[3:]
+		}
+	}
+
+	if p.Days > alldays || (p.Kind == KindRecurring && p.Days == 0) {
+		return fmt.Errorf("invalid days bitmask %03d", p.Days)
+	}
+
+	return nil
+}
+
+func parseClockGroups(groups []string) (time.Duration, error) {
+	hours, _ := strconv.Atoi(groups[0])
+	minutes, _ := strconv.Atoi(groups[1])
+	seconds, _ := strconv.Atoi(groups[2])
+
+	if hours > 23 || minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("invalid time %s:%s:%s", groups[0], groups[1], groups[2])
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second, nil
+}
+
+func formatClock(duration time.Duration) string {
+	seconds := int(duration / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// Pattern serializes the time pattern for the bridge
+func (p TimePattern) Pattern() string {
+	var output string
+
+	switch p.Kind {
+	case KindAbsolute:
+		output = p.Date.Format(dateTimeFormat)
+	case KindRecurring:
+		output = fmt.Sprintf("W%03d/T%s", p.Days, formatClock(p.Time))
+	case KindInterval:
+		if p.Days != 0 {
+			output = fmt.Sprintf("W%03d/", p.Days)
+		}
+
+		return fmt.Sprintf("%sT%s/T%s", output, formatClock(p.Time), formatClock(p.End))
+	case KindTimer:
+		output = "PT" + formatClock(p.Time)
+	case KindRecurringTimer:
+		output = "R"
+		if p.Repeat != 0 {
+			output += fmt.Sprintf("%02d", p.Repeat)
+		}
+		output += "/PT" + formatClock(p.Time)
+	}
+
+	if p.Random != 0 {
+		output += "A" + formatClock(p.Random)
+	}
+
+	return output
+}
+
+// String formats the time pattern to a human readable version
+func (p TimePattern) String() string {
+	var output string
+
+	switch p.Kind {
+	case KindAbsolute:
+		output = p.Date.Format("Mon 2 Jan 2006 at 15:04:05")
+	case KindRecurring:
+		output = fmt.Sprintf("%s at %s", recurrenceStr(p.Days), formatClock(p.Time))
+	case KindInterval:
+		output = fmt.Sprintf("%s from %s to %s", recurrenceStr(p.days()), formatClock(p.Time), formatClock(p.End))
+	case KindTimer:
+		output = fmt.Sprintf("In %s", formatClock(p.Time))
+	case KindRecurringTimer:
+		output = fmt.Sprintf("Every %s", formatClock(p.Time))
+		if p.Repeat != 0 {
+			output += fmt.Sprintf(", %d times", p.Repeat)
+		}
+	}
+
+	if p.Random != 0 {
+		output += fmt.Sprintf(", randomized by up to %s", formatClock(p.Random))
+	}
+
+	return output
+}
+
+func (p TimePattern) days() int {
+	if p.Days == 0 {
+		return alldays
+	}
+
+	return p.Days
+}
+
+// Next computes the earliest next occurrence after now, in the location of now. Timers depend on their start time, so they have none
+func (p TimePattern) Next(now time.Time) (time.Time, bool) {
+	switch p.Kind {
+	case KindAbsolute:
+		year, month, day := p.Date.Date()
+		date := time.Date(year, month, day, p.Date.Hour(), p.Date.Minute(), p.Date.Second(), 0, now.Location())
+
+		return date, date.After(now)
+
+	case KindRecurring, KindInterval:
+		year, month, day := now.Date()
+		midnight := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+		for offset := 0; offset <= 7; offset++ {
+			date := midnight.AddDate(0, 0, offset)
+			occurrence := date.Add(p.Time)
+
+			if p.days()&dayBit(date.Weekday()) != 0 && occurrence.After(now) {
+				return occurrence, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func dayBit(weekday time.Weekday) int {
+	if weekday == time.Sunday {
+		return sunday
+	}
+
+	return monday >> (weekday - time.Monday)
+}
@@ -0,0 +1,244 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimePattern(t *testing.T) {
+	type args struct {
+		value string
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      PatternKind
+		wantErr   bool
+	}{
+		{
+			"absolute",
+			args{
+				value: "2026-10-20T07:30:00",
+			},
+			KindAbsolute,
+			false,
+		},
+		{
+			"randomized absolute",
+			args{
+				value: "2026-10-20T07:30:00A00:15:00",
+			},
+			KindAbsolute,
+			false,
+		},
+		{
+			"recurring",
+			args{
+				value: "W124/T07:55:00",
+			},
+			KindRecurring,
+			false,
+		},
+		{
+			"randomized recurring",
+			args{
+				value: "W127/T19:00:00A01:00:00",
+			},
+			KindRecurring,
+			false,
+		},
+		{
+			"interval",
+			args{
+				value: "W003/T10:00:00/T12:00:00",
+			},
+			KindInterval,
+			false,
+		},
+		{
+			"daily interval",
+			args{
+				value: "T22:00:00/T06:00:00",
+			},
+			KindInterval,
+			false,
+		},
+		{
+			"timer",
+			args{
+				value: "PT00:30:00",
+			},
+			KindTimer,
+			false,
+		},
+		{
+			"randomized timer",
+			args{
+				value: "PT00:30:00A00:05:00",
+			},
+			KindTimer,
+			false,
+		},
+		{
+			"recurring timer",
+			args{
+				value: "R/PT01:00:00",
+			},
+			KindRecurringTimer,
+			false,
+		},
+		{
+			"limited recurring timer",
+			args{
+				value: "R05/PT00:10:00A00:01:00",
+			},
+			KindRecurringTimer,
+			false,
+		},
+		{
+			"no day",
+			args{
+				value: "W000/T07:55:00",
+			},
+			"",
+			true,
+		},
+		{
+			"out of range bitmask",
+			args{
+				value: "W200/T07:55:00",
+			},
+			"",
+			true,
+		},
+		{
+			"out of range time",
+			args{
+				value: "W127/T25:00:00",
+			},
+			"",
+			true,
+		},
+		{
+			"malformed",
+			args{
+				value: "W127 07:55",
+			},
+			"",
+			true,
+		},
+		{
+			"invalid date",
+			args{
+				value: "2026-02-30T07:30:00",
+			},
+			"",
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got, err := ParseTimePattern(tc.args.value)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ParseTimePattern() error = %v, wantErr %t", err, tc.wantErr)
+				return
+			}
+
+			if got.Kind != tc.want {
+				t.Errorf("ParseTimePattern() = %s, want %s", got.Kind, tc.want)
+			}
+
+			if err == nil && got.Pattern() != tc.args.value {
+				t.Errorf("Pattern() = `%s`, want `%s`", got.Pattern(), tc.args.value)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	now := time.Date(2021, 10, 16, 12, 0, 0, 0, time.UTC) // Saturday
+
+	type args struct {
+		value string
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+		wantOk    bool
+	}{
+		{
+			"later today",
+			args{
+				value: "W127/T19:00:00",
+			},
+			"2021-10-16T19:00:00",
+			true,
+		},
+		{
+			"next week day",
+			args{
+				value: "W124/T07:55:00A00:30:00",
+			},
+			"2021-10-18T07:55:00",
+			true,
+		},
+		{
+			"next week",
+			args{
+				value: "W002/T08:00:00",
+			},
+			"2021-10-23T08:00:00",
+			true,
+		},
+		{
+			"interval",
+			args{
+				value: "T22:00:00/T06:00:00",
+			},
+			"2021-10-16T22:00:00",
+			true,
+		},
+		{
+			"absolute",
+			args{
+				value: "2021-12-24T20:00:00",
+			},
+			"2021-12-24T20:00:00",
+			true,
+		},
+		{
+			"past absolute",
+			args{
+				value: "2021-10-01T20:00:00",
+			},
+			"2021-10-01T20:00:00",
+			false,
+		},
+		{
+			"timer",
+			args{
+				value: "PT00:30:00",
+			},
+			"0001-01-01T00:00:00",
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			pattern, err := ParseTimePattern(tc.args.value)
+			if err != nil {
+				t.Fatalf("ParseTimePattern() error = %s", err)
+			}
+
+			got, ok := pattern.Next(now)
+			if got.Format(dateTimeFormat) != tc.want || ok != tc.wantOk {
+				t.Errorf("Next() = (%s, %t), want (%s, %t)", got.Format(dateTimeFormat), ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package bridge
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	bridgeIDRegex = regexp.MustCompile(`^[0-9a-f]{16}$`)
+)
+
+// NewTLSConfig creates a TLS configuration accepting only a Hue bridge certificate.
+//
+// Bridges present a certificate issued for their bridge ID, not their IP, so hostname is not verified. The certificate is
+// checked against the Hue root CA when caFile is provided, or pinned on first use in pinFile and checked against it afterwards.
+func NewTLSConfig(caFile, pinFile string) (*tls.Config, error) {
+	var roots *x509.CertPool
+
+	if len(caFile) != 0 {
+		content, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read bridge CA: %s", err)
+		}
+
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no certificate found in bridge CA `%s`", caFile)
+		}
+	} else if len(pinFile) == 0 {
+		return nil, errors.New("a bridge CA or a pin file is required for checking bridge certificate")
+	}
+
+	var mutex sync.Mutex
+	var pinned []byte
+
+	if roots == nil {
+		var err error
+		if pinned, err = readPin(pinFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // hostname can't match, verification is done below
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no certificate presented by bridge")
+			}
+
+			leaf, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return fmt.Errorf("unable to parse bridge certificate: %s", err)
+			}
+
+			if !bridgeIDRegex.MatchString(strings.ToLower(leaf.Subject.CommonName)) {
+				return fmt.Errorf("certificate is issued for `%s`, not a bridge ID", leaf.Subject.CommonName)
+			}
+
+			if roots != nil {
+				intermediates := x509.NewCertPool()
+				for _, raw := range rawCerts[1:] {
+					if cert, err := x509.ParseCertificate(raw); err == nil {
+						intermediates.AddCert(cert)
+					}
+				}
+
+				if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+					return fmt.Errorf("untrusted bridge certificate: %s", err)
+				}
+
+				return nil
+			}
+
+			fingerprint := sha256.Sum256(rawCerts[0])
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if pinned == nil {
+				if err := os.WriteFile(pinFile, []byte(hex.EncodeToString(fingerprint[:])+"\n"), 0o600); err != nil {
+					return fmt.Errorf("unable to write bridge pin: %s", err)
+				}
+
+				pinned = fingerprint[:]
+			} else if !bytes.Equal(pinned, fingerprint[:]) {
+				return fmt.Errorf("bridge certificate has changed since first connection, remove `%s` if it's expected", pinFile)
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+// readPin reads the fingerprint pinned on first use, nil if none has been pinned yet
+func readPin(pinFile string) ([]byte, error) {
+	content, err := os.ReadFile(pinFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read bridge pin: %s", err)
+	}
+
+	fingerprint, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(fingerprint) != sha256.Size {
+		return nil, fmt.Errorf("malformed bridge pin in `%s`, must be a hex encoded SHA-256", pinFile)
+	}
+
+	return fingerprint, nil
+}
+
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return transport
+}
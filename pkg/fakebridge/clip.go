@@ -0,0 +1,212 @@
+package fakebridge
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	applicationKeyHeader = "hue-application-key"
+	eventBufferSize      = 32
+)
+
+func (b *Bridge) handleClip(w http.ResponseWriter, r *http.Request, resourceType string) {
+	if r.Header.Get(applicationKeyHeader) != b.username {
+		w.WriteHeader(http.StatusForbidden)
+		writeJSON(w, object{"errors": []object{{"description": "unauthorized user"}}, "data": []object{}})
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeJSON(w, object{"errors": []object{{"description": "method not allowed"}}, "data": []object{}})
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	resources := b.clipResources(resourceType)
+	if resources == nil {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(w, object{"errors": []object{{"description": fmt.Sprintf("resource type %s not found", resourceType)}}, "data": []object{}})
+		return
+	}
+
+	writeJSON(w, object{"errors": []object{}, "data": resources})
+}
+
+func (b *Bridge) clipResources(resourceType string) []object {
+	output := make([]object, 0)
+
+	switch resourceType {
+	case "light":
+		for _, id := range sortedIDs(b.resources["lights"]) {
+			output = append(output, b.lightResource(id))
+		}
+	case "grouped_light":
+		for _, id := range sortedIDs(b.resources["groups"]) {
+			state := child(b.resources["groups"][id], "state")
+			output = append(output, object{"id": resourceID("grouped_light", id), "id_v1": "/groups/" + id, "type": "grouped_light", "on": object{"on": state["any_on"]}})
+		}
+	case "motion", "temperature", "device_power":
+		for _, id := range sortedIDs(b.resources["sensors"]) {
+			if resource := b.sensorResource(id, resourceType); resource != nil {
+				output = append(output, resource)
+			}
+		}
+	default:
+		return nil
+	}
+
+	return output
+}
+
+func (b *Bridge) lightResource(id string) object {
+	light := b.resources["lights"][id]
+	state := child(light, "state")
+
+	resource := object{
+		"id":       resourceID("light", id),
+		"id_v1":    "/lights/" + id,
+		"type":     "light",
+		"metadata": object{"name": light["name"]},
+		"on":       object{"on": state["on"]},
+	}
+
+	if bri, ok := state["bri"].(float64); ok {
+		resource["dimming"] = object{"brightness": bri * 100 / 254}
+	}
+
+	return resource
+}
+
+func (b *Bridge) sensorResource(id, resourceType string) object {
+	sensor := b.resources["sensors"][id]
+	state := child(sensor, "state")
+	config := child(sensor, "config")
+
+	resource := object{
+		"id":    resourceID(resourceType, id),
+		"id_v1": "/sensors/" + id,
+		"type":  resourceType,
+	}
+
+	switch {
+	case resourceType == "motion" && sensor["type"] == "ZLLPresence":
+		resource["motion"] = object{"motion": state["presence"], "motion_valid": true}
+	case resourceType == "temperature" && sensor["type"] == "ZLLTemperature":
+		temperature, _ := state["temperature"].(float64)
+		resource["temperature"] = object{"temperature": temperature / 100, "temperature_valid": true}
+	case resourceType == "device_power" && sensor["type"] == "ZLLPresence" && config["battery"] != nil:
+		resource["power_state"] = object{"battery_level": config["battery"], "battery_state": "normal"}
+	default:
+		return nil
+	}
+
+	return resource
+}
+
+func (b *Bridge) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(applicationKeyHeader) != b.username {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	events := make(chan []byte, eventBufferSize)
+
+	b.mutex.Lock()
+	b.subscribers[events] = struct{}{}
+	b.mutex.Unlock()
+
+	defer func() {
+		b.mutex.Lock()
+		delete(b.subscribers, events)
+		b.mutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": hi\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if _, err := w.Write(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (b *Bridge) publish(resources ...object) {
+	if len(resources) == 0 || len(b.subscribers) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	id := resourceID("event", now.String())
+
+	content, err := json.Marshal([]object{{
+		"creationtime": now.Format(time.RFC3339),
+		"id":           id,
+		"type":         "update",
+		"data":         resources,
+	}})
+	if err != nil {
+		return
+	}
+
+	event := []byte(fmt.Sprintf("id: %d:0\ndata: %s\n\n", now.Unix(), content))
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+func (b *Bridge) publishLights(ids ...string) {
+	resources := make([]object, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := b.resources["lights"][id]; ok {
+			resources = append(resources, b.lightResource(id))
+		}
+	}
+
+	b.publish(resources...)
+}
+
+func (b *Bridge) publishSensor(id string) {
+	var resources []object
+	for _, resourceType := range []string{"motion", "temperature", "device_power"} {
+		if resource := b.sensorResource(id, resourceType); resource != nil {
+			resources = append(resources, resource)
+		}
+	}
+
+	b.publish(resources...)
+}
+
+func resourceID(resourceType, id string) string {
+	hash := sha256.Sum256([]byte(resourceType + "/" + id))
+	hex := fmt.Sprintf("%x", hash[:16])
+
+	return strings.Join([]string{hex[:8], hex[8:12], hex[12:16], hex[16:20], hex[20:32]}, "-")
+}
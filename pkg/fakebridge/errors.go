@@ -0,0 +1,33 @@
+package fakebridge
+
+import (
+	"fmt"
+)
+
+const (
+	errorUnauthorizedUser      = 1
+	errorInvalidJSON           = 2
+	errorResourceNotAvailable  = 3
+	errorMethodNotAvailable    = 4
+	errorMissingParameters     = 5
+	errorParameterNotAvailable = 6
+	errorInvalidValue          = 7
+)
+
+func apiError(errorType int, address, description string) object {
+	return object{
+		"error": object{
+			"type":        errorType,
+			"address":     address,
+			"description": description,
+		},
+	}
+}
+
+func notAvailable(address string) object {
+	return apiError(errorResourceNotAvailable, address, fmt.Sprintf("resource, %s, not available", address))
+}
+
+func methodNotAvailable(method, address string) object {
+	return apiError(errorMethodNotAvailable, address, fmt.Sprintf("method, %s, not available for resource, %s", method, address))
+}
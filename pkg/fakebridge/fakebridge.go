@@ -0,0 +1,641 @@
+package fakebridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sceneIDLength = 15
+	sceneIDChars  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	timeFormat    = "2006-01-02T15:04:05"
+
+	maxNameLength = 32
+	maxRuleItems  = 8
+)
+
+type object = map[string]interface{}
+
+// Bridge is an in-memory fake of a Hue bridge, serving the v1 API
+type Bridge struct {
+	resources   map[string]map[string]object
+	subscribers map[chan []byte]struct{}
+	config      object
+	username    string
+	random      *rand.Rand
+	mutex       sync.Mutex
+}
+
+// New creates a fake bridge with a default home, accepting given username
+func New(username string) *Bridge {
+	return &Bridge{
+		username:    username,
+		resources:   defaultResources(),
+		subscribers: make(map[chan []byte]struct{}),
+		config:      defaultConfig(),
+		random:      rand.New(rand.NewSource(1)),
+	}
+}
+
+// NewServer creates a fake bridge and starts serving it with httptest
+func NewServer(username string) (*Bridge, *httptest.Server) {
+	fake := New(username)
+	return fake, httptest.NewServer(fake)
+}
+
+// NewTLSServer creates a fake bridge and starts serving it over HTTPS with httptest, for the CLIP v2 API
+func NewTLSServer(username string) (*Bridge, *httptest.Server) {
+	fake := New(username)
+
+	tlsConfig, err := fake.TLSConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	server := httptest.NewUnstartedServer(fake)
+	server.TLS = tlsConfig
+	server.StartTLS()
+
+	return fake, server
+}
+
+// Address returns the host:port of given server, to be used as a bridge IP
+func Address(server *httptest.Server) string {
+	return strings.TrimPrefix(strings.TrimPrefix(server.URL, "https://"), "http://")
+}
+
+// Get returns a copy of a resource, e.g. `Get("lights", "1")`
+func (b *Bridge) Get(resource, id string) (map[string]interface{}, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	item, ok := b.resources[resource][id]
+	if !ok {
+		return nil, false
+	}
+
+	return copyObject(item), true
+}
+
+// Count returns the number of items of a resource
+func (b *Bridge) Count(resource string) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.resources[resource])
+}
+
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/eventstream/clip/v2" {
+		b.handleEventStream(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/clip/v2/resource/") {
+		b.handleClip(w, r, strings.TrimPrefix(r.URL.Path, "/clip/v2/resource/"))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "api" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(parts) == 1 {
+		b.handlePair(w, r)
+		return
+	}
+
+	if parts[1] != b.username {
+		writeJSON(w, []object{apiError(errorUnauthorizedUser, "/", "unauthorized user")})
+		return
+	}
+
+	var body object
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, []object{apiError(errorInvalidJSON, "", "body contains invalid json")})
+			return
+		}
+	}
+
+	writeJSON(w, b.route(r.Method, parts[2:], body))
+}
+
+func (b *Bridge) handlePair(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, []object{apiError(errorMethodNotAvailable, "/", fmt.Sprintf("method, %s, not available for resource, /", r.Method))})
+		return
+	}
+
+	var body object
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, []object{apiError(errorInvalidJSON, "", "body contains invalid json")})
+		return
+	}
+
+	if _, ok := body["devicetype"].(string); !ok {
+		writeJSON(w, []object{apiError(errorMissingParameters, "/", "invalid/missing parameters in body")})
+		return
+	}
+
+	writeJSON(w, []object{{"success": object{"username": b.username}}})
+}
+
+func (b *Bridge) route(method string, parts []string, body object) interface{} {
+	address := "/" + strings.Join(parts, "/")
+
+	if len(parts) == 0 {
+		if method != http.MethodGet {
+			return []object{methodNotAvailable(method, address)}
+		}
+
+		return b.fullState()
+	}
+
+	resource := parts[0]
+
+	if resource == "config" {
+		if method != http.MethodGet {
+			return []object{methodNotAvailable(method, address)}
+		}
+
+		now := time.Now()
+		b.config["UTC"] = now.UTC().Format(timeFormat)
+
+		if location, err := time.LoadLocation(b.config["timezone"].(string)); err == nil {
+			now = now.In(location)
+		}
+		b.config["localtime"] = now.Format(timeFormat)
+
+		return b.config
+	}
+
+	items, ok := b.resources[resource]
+	if !ok {
+		return []object{notAvailable(address)}
+	}
+
+	if len(parts) == 1 {
+		switch method {
+		case http.MethodGet:
+			return b.list(resource)
+		case http.MethodPost:
+			return b.create(resource, body)
+		default:
+			return []object{methodNotAvailable(method, address)}
+		}
+	}
+
+	item, ok := items[parts[1]]
+	if !ok {
+		return []object{notAvailable(address)}
+	}
+
+	switch {
+	case len(parts) == 2 && method == http.MethodGet:
+		return item
+	case len(parts) == 2 && method == http.MethodPut:
+		if err := validateResource(resource, address, body); err != nil {
+			return []object{err}
+		}
+
+		return update(item, address, body)
+	case len(parts) == 2 && method == http.MethodDelete:
+		delete(items, parts[1])
+		return []object{{"success": fmt.Sprintf("%s deleted", address)}}
+	case method != http.MethodPut:
+		return []object{methodNotAvailable(method, address)}
+	case resource == "lights" && len(parts) == 3 && parts[2] == "state":
+		results := setState(item, "state", address, body)
+		b.refreshGroups()
+		b.publishLights(parts[1])
+		return results
+	case resource == "groups" && len(parts) == 3 && parts[2] == "action":
+		return b.groupAction(parts[1], item, address, body)
+	case resource == "scenes" && len(parts) == 4 && parts[2] == "lightstates":
+		return b.sceneLightState(item, parts[3], address, body)
+	case resource == "sensors" && len(parts) == 3 && (parts[2] == "config" || parts[2] == "state"):
+		results := update(child(item, parts[2]), address, body)
+		b.publishSensor(parts[1])
+		return results
+	default:
+		return []object{notAvailable(address)}
+	}
+}
+
+func (b *Bridge) fullState() object {
+	output := object{"config": b.config}
+	for resource := range b.resources {
+		output[resource] = b.list(resource)
+	}
+
+	return output
+}
+
+func (b *Bridge) list(resource string) object {
+	output := make(object, len(b.resources[resource]))
+
+	for id, item := range b.resources[resource] {
+		if resource == "groups" && id == "0" {
+			continue
+		}
+
+		if resource == "scenes" {
+			item = copyObject(item)
+			delete(item, "lightstates")
+		}
+
+		output[id] = item
+	}
+
+	return output
+}
+
+func (b *Bridge) create(resource string, body object) []object {
+	if name, ok := body["name"].(string); !ok || len(name) == 0 {
+		return []object{apiError(errorMissingParameters, "/"+resource, "invalid/missing parameters in body")}
+	}
+
+	if err := validateResource(resource, "/"+resource, body); err != nil {
+		return []object{err}
+	}
+
+	now := time.Now().UTC().Format(timeFormat)
+
+	switch resource {
+	case "scenes":
+		if body["type"] == "GroupScene" {
+			group, ok := b.resources["groups"][fmt.Sprintf("%v", body["group"])]
+			if !ok {
+				return []object{apiError(errorInvalidValue, "/scenes/group", fmt.Sprintf("invalid value, %v, for parameter, group", body["group"]))}
+			}
+
+			lights := make([]interface{}, 0)
+			for _, lightID := range groupLights(group) {
+				lights = append(lights, lightID)
+			}
+
+			body["lights"] = lights
+		}
+
+		lights, ok := body["lights"].([]interface{})
+		if !ok || len(lights) == 0 {
+			return []object{apiError(errorMissingParameters, "/scenes", "invalid/missing parameters in body")}
+		}
+
+		lightstates := make(object, len(lights))
+		for _, light := range lights {
+			lightID := fmt.Sprintf("%v", light)
+
+			current, ok := b.resources["lights"][lightID]
+			if !ok {
+				return []object{apiError(errorInvalidValue, "/scenes/lights", fmt.Sprintf("invalid value, %s, for parameter, lights", lightID))}
+			}
+
+			lightstate := make(object)
+			for key, value := range child(current, "state") {
+				if key == "on" || key == "bri" {
+					lightstate[key] = value
+				}
+			}
+
+			lightstates[lightID] = lightstate
+		}
+
+		body["lightstates"] = lightstates
+		body["owner"] = b.username
+		body["lastupdated"] = now
+		body["version"] = 2
+		if _, ok := body["type"]; !ok {
+			body["type"] = "LightScene"
+		}
+
+	case "rules":
+		body["owner"] = b.username
+		body["created"] = now
+		body["lasttriggered"] = "none"
+		body["timestriggered"] = 0
+		body["status"] = "enabled"
+
+	case "resourcelinks":
+		body["owner"] = b.username
+
+	case "schedules":
+		body["created"] = now
+		if _, ok := body["status"]; !ok {
+			body["status"] = "enabled"
+		}
+	}
+
+	id := b.nextID(resource)
+	b.resources[resource][id] = body
+
+	return []object{{"success": object{"id": id}}}
+}
+
+// validateResource enforces limits of the bridge: names of 32 characters, rules of 8 conditions and 8 actions
+func validateResource(resource, address string, body object) object {
+	if name, ok := body["name"].(string); ok && len(name) > maxNameLength {
+		return apiError(errorInvalidValue, address+"/name", fmt.Sprintf("invalid value, %s, for parameter, name", name))
+	}
+
+	if resource != "rules" {
+		return nil
+	}
+
+	for _, key := range []string{"conditions", "actions"} {
+		if items, ok := body[key].([]interface{}); ok && len(items) > maxRuleItems {
+			return apiError(errorInvalidValue, address+"/"+key, fmt.Sprintf("invalid value, %d %s, for parameter, %s", len(items), key, key))
+		}
+	}
+
+	return nil
+}
+
+func (b *Bridge) nextID(resource string) string {
+	if resource == "scenes" {
+		id := make([]byte, sceneIDLength)
+		for index := range id {
+			id[index] = sceneIDChars[b.random.Intn(len(sceneIDChars))]
+		}
+
+		return string(id)
+	}
+
+	max := 0
+	for id := range b.resources[resource] {
+		if value, err := strconv.Atoi(id); err == nil && value > max {
+			max = value
+		}
+	}
+
+	return strconv.Itoa(max + 1)
+}
+
+func (b *Bridge) groupAction(id string, group object, address string, body object) []object {
+	lights := groupLights(group)
+	if id == "0" {
+		lights = sortedIDs(b.resources["lights"])
+	}
+
+	if sceneID, ok := body["scene"]; ok {
+		scene, ok := b.resources["scenes"][fmt.Sprintf("%v", sceneID)]
+		if !ok {
+			return []object{apiError(errorInvalidValue, address+"/scene", fmt.Sprintf("invalid value, %v, for parameter, scene", sceneID))}
+		}
+
+		lights = sortedKeys(child(scene, "lightstates"))
+		for _, lightID := range lights {
+			if light, ok := b.resources["lights"][lightID]; ok {
+				setState(light, "state", "", child(scene, "lightstates")[lightID].(object))
+			}
+		}
+
+		b.refreshGroups()
+		b.publishLights(lights...)
+
+		return []object{{"success": object{address + "/scene": sceneID}}}
+	}
+
+	results := setState(group, "action", address, body)
+	for _, lightID := range lights {
+		if light, ok := b.resources["lights"][lightID]; ok {
+			setState(light, "state", "", body)
+		}
+	}
+
+	b.refreshGroups()
+	b.publishLights(lights...)
+
+	return results
+}
+
+func (b *Bridge) sceneLightState(scene object, lightID, address string, body object) []object {
+	if !contains(groupLights(scene), lightID) {
+		return []object{notAvailable(address)}
+	}
+
+	holder := make(object)
+	results := setState(holder, "lightstate", address, body)
+
+	child(scene, "lightstates")[lightID] = holder["lightstate"]
+
+	return results
+}
+
+func (b *Bridge) refreshGroups() {
+	for id, group := range b.resources["groups"] {
+		lights := groupLights(group)
+		if id == "0" {
+			lights = sortedIDs(b.resources["lights"])
+		}
+
+		anyOn := false
+		allOn := len(lights) != 0
+
+		for _, lightID := range lights {
+			on, _ := child(b.resources["lights"][lightID], "state")["on"].(bool)
+			anyOn = anyOn || on
+			allOn = allOn && on
+		}
+
+		group["state"] = object{"any_on": anyOn, "all_on": allOn}
+	}
+}
+
+func update(item object, address string, body object) []object {
+	results := make([]object, 0, len(body))
+
+	for _, key := range sortedKeys(body) {
+		item[key] = body[key]
+		results = append(results, object{"success": object{fmt.Sprintf("%s/%s", address, key): body[key]}})
+	}
+
+	return results
+}
+
+func setState(item object, key, address string, body object) []object {
+	state := child(item, key)
+	results := make([]object, 0, len(body))
+
+	for _, name := range sortedKeys(body) {
+		value := body[name]
+
+		if err := validateStateValue(fmt.Sprintf("%s/%s", address, name), name, value); err != nil {
+			results = append(results, err)
+			continue
+		}
+
+		switch {
+		case name == "bri_inc" || name == "ct_inc":
+			if key == "state" {
+				increment(state, strings.TrimSuffix(name, "_inc"), value.(float64))
+			}
+		case name != "transitiontime" || key == "lightstate":
+			state[name] = value
+		}
+
+		if key == "state" {
+			switch name {
+			case "ct", "ct_inc":
+				state["colormode"] = "ct"
+			case "xy":
+				state["colormode"] = "xy"
+			case "hue", "sat":
+				state["colormode"] = "hs"
+			}
+		}
+
+		results = append(results, object{"success": object{fmt.Sprintf("%s/%s", address, name): value}})
+	}
+
+	return results
+}
+
+func increment(state object, name string, step float64) {
+	min, max := 1.0, 254.0
+	if name == "ct" {
+		min, max = 153, 500
+	}
+
+	current, _ := state[name].(float64)
+	state[name] = math.Max(min, math.Min(max, current+step))
+}
+
+func validateStateValue(address, name string, value interface{}) object {
+	invalid := apiError(errorInvalidValue, address, fmt.Sprintf("invalid value, %v, for parameter, %s", value, name))
+
+	checkRange := func(min, max float64) object {
+		number, ok := value.(float64)
+		if !ok || number < min || number > max {
+			return invalid
+		}
+
+		return nil
+	}
+
+	switch name {
+	case "on":
+		if _, ok := value.(bool); !ok {
+			return invalid
+		}
+	case "bri":
+		return checkRange(0, 255)
+	case "ct":
+		return checkRange(153, 500)
+	case "hue":
+		return checkRange(0, 65535)
+	case "sat":
+		return checkRange(0, 254)
+	case "transitiontime":
+		return checkRange(0, 65535)
+	case "bri_inc":
+		return checkRange(-254, 254)
+	case "ct_inc":
+		return checkRange(-65534, 65534)
+	case "effect":
+		if value != "none" && value != "colorloop" {
+			return invalid
+		}
+	case "alert":
+		if value != "none" && value != "select" && value != "lselect" {
+			return invalid
+		}
+	case "xy":
+		coordinates, ok := value.([]interface{})
+		if !ok || len(coordinates) != 2 {
+			return invalid
+		}
+
+		for _, coordinate := range coordinates {
+			if number, ok := coordinate.(float64); !ok || number < 0 || number > 1 {
+				return invalid
+			}
+		}
+	default:
+		return apiError(errorParameterNotAvailable, address, fmt.Sprintf("parameter, %s, not available", name))
+	}
+
+	return nil
+}
+
+func child(item object, key string) object {
+	value, ok := item[key].(object)
+	if !ok {
+		value = make(object)
+		item[key] = value
+	}
+
+	return value
+}
+
+func groupLights(item object) []string {
+	raw, _ := item["lights"].([]interface{})
+
+	lights := make([]string, 0, len(raw))
+	for _, light := range raw {
+		lights = append(lights, fmt.Sprintf("%v", light))
+	}
+
+	return lights
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(item object) []string {
+	keys := make([]string, 0, len(item))
+	for key := range item {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedIDs(items map[string]object) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func copyObject(item object) object {
+	content, _ := json.Marshal(item)
+
+	var output object
+	_ = json.Unmarshal(content, &output)
+
+	return output
+}
+
+func writeJSON(w http.ResponseWriter, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,176 @@
+package fakebridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func canonical(t *testing.T, content string) string {
+	t.Helper()
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		t.Fatalf("unable to parse `%s`: %s", content, err)
+	}
+
+	output, _ := json.Marshal(value)
+	return string(output)
+}
+
+func TestServeHTTP(t *testing.T) {
+	longName := strings.Repeat("a", 33)
+	actions := strings.TrimSuffix(strings.Repeat(`{"address":"/groups/1/action","method":"PUT","body":{"on":true}},`, 9), ",")
+
+	type args struct {
+		method string
+		path   string
+		body   string
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+	}{
+		{
+			"unauthorized user",
+			args{method: http.MethodGet, path: "/api/unknown/lights"},
+			`[{"error":{"type":1,"address":"/","description":"unauthorized user"}}]`,
+		},
+		{
+			"invalid json",
+			args{method: http.MethodPut, path: "/api/secret/lights/1/state", body: "{"},
+			`[{"error":{"type":2,"address":"","description":"body contains invalid json"}}]`,
+		},
+		{
+			"resource not available",
+			args{method: http.MethodGet, path: "/api/secret/lights/42"},
+			`[{"error":{"type":3,"address":"/lights/42","description":"resource, /lights/42, not available"}}]`,
+		},
+		{
+			"method not available",
+			args{method: http.MethodPost, path: "/api/secret/config", body: "{}"},
+			`[{"error":{"type":4,"address":"/config","description":"method, POST, not available for resource, /config"}}]`,
+		},
+		{
+			"missing parameters",
+			args{method: http.MethodPost, path: "/api/secret/rules", body: "{}"},
+			`[{"error":{"type":5,"address":"/rules","description":"invalid/missing parameters in body"}}]`,
+		},
+		{
+			"partial success",
+			args{method: http.MethodPut, path: "/api/secret/lights/1/state", body: `{"on":true,"bri":300,"speed":1}`},
+			`[{"error":{"type":7,"address":"/lights/1/state/bri","description":"invalid value, 300, for parameter, bri"}},{"success":{"/lights/1/state/on":true}},{"error":{"type":6,"address":"/lights/1/state/speed","description":"parameter, speed, not available"}}]`,
+		},
+		{
+			"name too long",
+			args{method: http.MethodPost, path: "/api/secret/schedules", body: `{"name":"` + longName + `"}`},
+			`[{"error":{"type":7,"address":"/schedules/name","description":"invalid value, ` + longName + `, for parameter, name"}}]`,
+		},
+		{
+			"too many actions",
+			args{method: http.MethodPost, path: "/api/secret/rules", body: `{"name":"Tap 2.1","actions":[` + actions + `]}`},
+			`[{"error":{"type":7,"address":"/rules/actions","description":"invalid value, 9 actions, for parameter, actions"}}]`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+			New("secret").ServeHTTP(writer, httptest.NewRequest(tc.args.method, tc.args.path, strings.NewReader(tc.args.body)))
+
+			if got := canonical(t, writer.Body.String()); got != canonical(t, tc.want) {
+				t.Errorf("ServeHTTP() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	_, server := NewServer("secret")
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/eventstream/clip/v2", nil)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unable to open event stream: %s", err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusForbidden {
+		t.Errorf("event stream without key = HTTP/%d, want HTTP/%d", response.StatusCode, http.StatusForbidden)
+	}
+
+	request.Header.Set(applicationKeyHeader, "secret")
+
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unable to open event stream: %s", err)
+	}
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	if line, err := reader.ReadString('\n'); err != nil || line != ": hi\n" {
+		t.Fatalf("event stream opening = `%s`, %v, want `: hi`", line, err)
+	}
+
+	update, _ := http.NewRequest(http.MethodPut, server.URL+"/api/secret/lights/3/state", strings.NewReader(`{"on":true,"bri":254}`))
+	if response, err := http.DefaultClient.Do(update); err != nil {
+		t.Fatalf("unable to update light: %s", err)
+	} else {
+		response.Body.Close()
+	}
+
+	lines := make(chan string)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+
+			if strings.HasPrefix(line, "data: ") {
+				lines <- strings.TrimPrefix(strings.TrimSpace(line), "data: ")
+			}
+		}
+	}()
+
+	var data string
+	select {
+	case data = <-lines:
+	case <-time.After(time.Second * 5):
+		t.Fatal("no event received")
+	}
+
+	var events []struct {
+		Type string `json:"type"`
+		Data []struct {
+			IDV1    string            `json:"id_v1"`
+			Type    string            `json:"type"`
+			On      struct{ On bool } `json:"on"`
+			Dimming struct {
+				Brightness float64 `json:"brightness"`
+			} `json:"dimming"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal([]byte(data), &events); err != nil {
+		t.Fatalf("unable to parse event `%s`: %s", data, err)
+	}
+
+	if len(events) != 1 || events[0].Type != "update" || len(events[0].Data) != 1 {
+		t.Fatalf("event = %s, want a single update", data)
+	}
+
+	light := events[0].Data[0]
+	if light.IDV1 != "/lights/3" || light.Type != "light" || !light.On.On || light.Dimming.Brightness != 100 {
+		t.Errorf("event = %s, want light 3 on at 100%%", data)
+	}
+}
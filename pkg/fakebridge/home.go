@@ -0,0 +1,89 @@
+package fakebridge
+
+import (
+	"encoding/json"
+)
+
+// defaultHome is a small home: two rooms of color lights with scenes, a schedule and a rule from the official app, a plug, a motion sensor and a tap
+const defaultHome = `{
+	"lights": {
+		"1": {"name": "Living room lamp", "type": "Extended color light", "modelid": "LCT015", "manufacturername": "Signify Netherlands B.V.", "productname": "Hue color lamp", "uniqueid": "00:17:88:01:00:00:00:01-0b", "swversion": "1.88.1",
+			"state": {"on": false, "bri": 254, "hue": 8417, "sat": 140, "ct": 366, "xy": [0.4573, 0.41], "effect": "none", "alert": "none", "colormode": "ct", "mode": "homeautomation", "reachable": true},
+			"capabilities": {"control": {"mindimlevel": 1000, "maxlumen": 806, "colorgamuttype": "C", "colorgamut": [[0.6915, 0.3083], [0.17, 0.7], [0.1532, 0.0475]], "ct": {"min": 153, "max": 500}}}},
+		"2": {"name": "Living room spot", "type": "Color temperature light", "modelid": "LTW013", "manufacturername": "Signify Netherlands B.V.", "productname": "Hue ambiance spot", "uniqueid": "00:17:88:01:00:00:00:02-0b", "swversion": "1.88.1",
+			"state": {"on": false, "bri": 254, "ct": 366, "alert": "none", "colormode": "ct", "mode": "homeautomation", "reachable": true},
+			"capabilities": {"control": {"mindimlevel": 2000, "maxlumen": 250, "ct": {"min": 153, "max": 454}}}},
+		"3": {"name": "Bedroom lamp", "type": "Extended color light", "modelid": "LCT015", "manufacturername": "Signify Netherlands B.V.", "productname": "Hue color lamp", "uniqueid": "00:17:88:01:00:00:00:03-0b", "swversion": "1.88.1",
+			"state": {"on": false, "bri": 254, "hue": 8417, "sat": 140, "ct": 366, "xy": [0.4573, 0.41], "effect": "none", "alert": "none", "colormode": "ct", "mode": "homeautomation", "reachable": true},
+			"capabilities": {"control": {"mindimlevel": 1000, "maxlumen": 806, "colorgamuttype": "C", "colorgamut": [[0.6915, 0.3083], [0.17, 0.7], [0.1532, 0.0475]], "ct": {"min": 153, "max": 500}}}},
+		"4": {"name": "Christmas tree", "type": "On/Off plug-in unit", "modelid": "LOM001", "manufacturername": "Signify Netherlands B.V.", "productname": "Hue Smart plug", "uniqueid": "00:17:88:01:00:00:00:04-0b", "swversion": "1.88.1",
+			"state": {"on": false, "alert": "select", "mode": "homeautomation", "reachable": true},
+			"capabilities": {"control": {}}}
+	},
+	"groups": {
+		"0": {"name": "Group 0", "type": "LightGroup", "lights": [], "sensors": [], "action": {"on": false}, "state": {"all_on": false, "any_on": false}},
+		"1": {"name": "Living room", "type": "Room", "class": "Living room", "lights": ["1", "2"], "sensors": [], "action": {"on": false, "bri": 254, "ct": 366}, "state": {"all_on": false, "any_on": false}, "recycle": false},
+		"2": {"name": "Bedroom", "type": "Room", "class": "Bedroom", "lights": ["3"], "sensors": [], "action": {"on": false, "bri": 254, "ct": 366}, "state": {"all_on": false, "any_on": false}, "recycle": false},
+		"3": {"name": "Christmas", "type": "Room", "class": "Other", "lights": ["4"], "sensors": [], "action": {"on": false}, "state": {"all_on": false, "any_on": false}, "recycle": false}
+	},
+	"sensors": {
+		"1": {"name": "Daylight", "type": "Daylight", "modelid": "PHDL00", "manufacturername": "Signify Netherlands B.V.", "swversion": "1.0",
+			"state": {"daylight": true, "lastupdated": "2021-01-01T07:42:00"}, "config": {"on": true, "configured": false, "sunriseoffset": 30, "sunsetoffset": -30}},
+		"5": {"name": "Hallway sensor", "type": "ZLLPresence", "modelid": "SML001", "manufacturername": "Signify Netherlands B.V.", "uniqueid": "00:17:88:01:00:00:00:05-02-0406", "swversion": "6.1.1.27575",
+			"state": {"presence": false, "lastupdated": "2021-01-01T08:00:00"}, "config": {"on": true, "battery": 87, "reachable": true, "ledindication": false, "sensitivity": 2, "sensitivitymax": 2}},
+		"6": {"name": "Hallway sensor", "type": "ZLLLightLevel", "modelid": "SML001", "manufacturername": "Signify Netherlands B.V.", "uniqueid": "00:17:88:01:00:00:00:05-02-0400", "swversion": "6.1.1.27575",
+			"state": {"lightlevel": 12000, "dark": true, "daylight": false, "lastupdated": "2021-01-01T08:00:00"}, "config": {"on": true, "battery": 87, "reachable": true, "ledindication": false, "tholddark": 16000, "tholdoffset": 7000}},
+		"7": {"name": "Hallway sensor", "type": "ZLLTemperature", "modelid": "SML001", "manufacturername": "Signify Netherlands B.V.", "uniqueid": "00:17:88:01:00:00:00:05-02-0402", "swversion": "6.1.1.27575",
+			"state": {"temperature": 2017, "lastupdated": "2021-01-01T08:00:00"}, "config": {"on": true, "battery": 87, "reachable": true, "ledindication": false}},
+		"8": {"name": "Hue tap switch 1", "type": "ZGPSwitch", "modelid": "ZGPSWITCH", "manufacturername": "Philips", "uniqueid": "00:00:00:00:00:00:00:08-f2",
+			"state": {"buttonevent": 34, "lastupdated": "2021-01-01T08:00:00"}, "config": {"on": true}},
+		"9": {"name": "Hallway status", "type": "CLIPGenericStatus", "modelid": "GENERIC_STATUS", "manufacturername": "hue", "uniqueid": "hallway-status", "swversion": "1.0",
+			"state": {"status": 0, "lastupdated": "2021-01-01T08:00:00"}, "config": {"on": true, "reachable": true}},
+		"10": {"name": "Bedroom dimmer", "type": "ZLLSwitch", "modelid": "RWL021", "manufacturername": "Signify Netherlands B.V.", "uniqueid": "00:17:88:01:00:00:00:10-02-fc00", "swversion": "6.1.1.28573",
+			"state": {"buttonevent": 1002, "lastupdated": "2021-01-01T08:00:00"}, "config": {"on": true, "battery": 100, "reachable": true}},
+		"11": {"name": "Entrance button", "type": "ZLLSwitch", "modelid": "ROM001", "manufacturername": "Signify Netherlands B.V.", "uniqueid": "00:17:88:01:00:00:00:11-01-fc00", "swversion": "2.47.8",
+			"state": {"buttonevent": 1002, "lastupdated": "2021-01-01T08:00:00"}, "config": {"on": true, "battery": 100, "reachable": true}}
+	},
+	"scenes": {
+		"KvPlqRx3wYGz0Ab": {"name": "Relax", "type": "GroupScene", "group": "1", "lights": ["1", "2"], "owner": "official-app-username", "recycle": false, "locked": false, "appdata": {"version": 1, "data": "mB3xD_r01_d01"},
+			"lightstates": {"1": {"on": true, "bri": 144, "ct": 447}, "2": {"on": true, "bri": 144, "ct": 447}}},
+		"Tn7cWq2LsYd9HjE": {"name": "Concentrate", "type": "GroupScene", "group": "1", "lights": ["1", "2"], "owner": "official-app-username", "recycle": false, "locked": false, "appdata": {"version": 1, "data": "Jk4pQ_r01_d03"},
+			"lightstates": {"1": {"on": true, "bri": 254, "ct": 233}, "2": {"on": true, "bri": 254, "ct": 233}}},
+		"Zr5uNm8BvXc1GtK": {"name": "Nightlight", "type": "GroupScene", "group": "2", "lights": ["3"], "owner": "official-app-username", "recycle": false, "locked": false, "appdata": {"version": 1, "data": "Xa9vR_r02_d04"},
+			"lightstates": {"3": {"on": true, "bri": 1, "xy": [0.561, 0.4042]}}}
+	},
+	"schedules": {
+		"1": {"name": "Wake up", "description": "", "command": {"address": "/api/official-app-username/groups/2/action", "method": "PUT", "body": {"scene": "Zr5uNm8BvXc1GtK"}},
+			"localtime": "W124/T07:00:00", "time": "W124/T06:00:00", "created": "2021-01-01T08:00:00", "status": "enabled", "recycle": false}
+	},
+	"rules": {
+		"1": {"name": "Hallway at night", "owner": "official-app-username", "created": "2021-01-01T08:00:00", "lasttriggered": "none", "timestriggered": 0, "status": "enabled", "recycle": false,
+			"conditions": [{"address": "/sensors/5/state/presence", "operator": "eq", "value": "true"}, {"address": "/sensors/5/state/presence", "operator": "dx"}],
+			"actions": [{"address": "/groups/2/action", "method": "PUT", "body": {"scene": "Zr5uNm8BvXc1GtK"}}]}
+	},
+	"resourcelinks": {}
+}`
+
+func defaultResources() map[string]map[string]object {
+	var resources map[string]map[string]object
+	if err := json.Unmarshal([]byte(defaultHome), &resources); err != nil {
+		panic(err)
+	}
+
+	return resources
+}
+
+func defaultConfig() object {
+	return object{
+		"name":          "Fake Bridge",
+		"bridgeid":      "001788FFFE000000",
+		"modelid":       "BSB002",
+		"apiversion":    "1.46.0",
+		"swversion":     "1946157000",
+		"mac":           "00:17:88:00:00:00",
+		"ipaddress":     "127.0.0.1",
+		"timezone":      "Europe/Paris",
+		"zigbeechannel": 25,
+		"linkbutton":    false,
+	}
+}
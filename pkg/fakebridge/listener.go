@@ -0,0 +1,80 @@
+package fakebridge
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+)
+
+const (
+	tlsHandshakeRecord = 0x16
+)
+
+// ListenAndServe serves the fake bridge on given address, with both plain HTTP and HTTPS on the same port
+func (b *Bridge) ListenAndServe(address string) error {
+	tlsConfig, err := b.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return http.Serve(sniffListener{Listener: listener, tlsConfig: tlsConfig}, b)
+}
+
+type sniffListener struct {
+	net.Listener
+	tlsConfig *tls.Config
+}
+
+func (l sniffListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &sniffConn{Conn: conn, tlsConfig: l.tlsConfig}, nil
+}
+
+// sniffConn switches to TLS when the first byte received is a handshake
+type sniffConn struct {
+	net.Conn
+	tlsConfig *tls.Config
+	delegate  net.Conn
+	once      sync.Once
+}
+
+func (c *sniffConn) sniff() {
+	c.once.Do(func() {
+		buffered := bufferedConn{Conn: c.Conn, reader: bufio.NewReader(c.Conn)}
+		c.delegate = buffered
+
+		if first, err := buffered.reader.Peek(1); err == nil && first[0] == tlsHandshakeRecord {
+			c.delegate = tls.Server(buffered, c.tlsConfig)
+		}
+	})
+}
+
+func (c *sniffConn) Read(p []byte) (int, error) {
+	c.sniff()
+	return c.delegate.Read(p)
+}
+
+func (c *sniffConn) Write(p []byte) (int, error) {
+	c.sniff()
+	return c.delegate.Write(p)
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
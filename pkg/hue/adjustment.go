@@ -0,0 +1,309 @@
+package hue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	minKelvin = 2000
+	maxKelvin = 6535
+)
+
+// adjustment of lights, either a named state, a scene or fine-grained values
+type adjustment struct {
+	State          string    `json:"state"`
+	Scene          string    `json:"scene"`
+	On             *bool     `json:"on"`
+	Brightness     *int      `json:"brightness"`
+	BrightnessStep *int      `json:"brightnessStep"`
+	Kelvin         *int      `json:"kelvin"`
+	Mirek          *int      `json:"mirek"`
+	MirekStep      *int      `json:"mirekStep"`
+	TransitionTime *int      `json:"transitiontime"`
+	Color          string    `json:"color"`
+	RGB            []int     `json:"rgb"`
+	XY             []float64 `json:"xy"`
+}
+
+func (a adjustment) hasValues() bool {
+	return a.On != nil || a.Brightness != nil || a.BrightnessStep != nil || a.TransitionTime != nil || a.hasWhite() || a.hasColor()
+}
+
+func (a adjustment) hasWhite() bool {
+	return a.Kelvin != nil || a.Mirek != nil || a.MirekStep != nil
+}
+
+func (a adjustment) hasColor() bool {
+	return len(a.Color) != 0 || a.RGB != nil || a.XY != nil
+}
+
+func (a adjustment) validate() error {
+	var errs []error
+
+	checkRange := func(name string, value *int, min, max int) {
+		if value != nil && (*value < min || *value > max) {
+			errs = append(errs, newConfigError(name, "%d is out of range, must be between %d and %d", *value, min, max))
+		}
+	}
+
+	if len(a.State) != 0 || len(a.Scene) != 0 {
+		if a.hasValues() || (len(a.State) != 0 && len(a.Scene) != 0) {
+			errs = append(errs, newConfigError("state", "state and scene can't be combined with other values"))
+		}
+
+		return joinInvalid(errs)
+	}
+
+	if !a.hasValues() {
+		return model.WrapInvalid(fmt.Errorf("state, scene or at least one value is required"))
+	}
+
+	checkRange("brightness", a.Brightness, 1, 100)
+	checkRange("brightnessStep", a.BrightnessStep, -100, 100)
+	checkRange("kelvin", a.Kelvin, minKelvin, maxKelvin)
+	checkRange("mirek", a.Mirek, bridge.MinMirek, bridge.MaxMirek)
+	checkRange("mirekStep", a.MirekStep, -bridge.MaxMirek, bridge.MaxMirek)
+	checkRange("transitiontime", a.TransitionTime, 0, 65535)
+
+	if a.Brightness != nil && a.BrightnessStep != nil {
+		errs = append(errs, newConfigError("brightness", "only one of brightness or brightnessStep can be set"))
+	}
+
+	colors := 0
+	for _, set := range []bool{a.Kelvin != nil, a.Mirek != nil, a.MirekStep != nil, len(a.Color) != 0, a.RGB != nil, a.XY != nil} {
+		if set {
+			colors++
+		}
+	}
+
+	if colors > 1 {
+		errs = append(errs, newConfigError("color", "only one of kelvin, mirek, mirekStep, color, rgb or xy can be set"))
+	}
+
+	if len(a.Color) != 0 {
+		if _, _, _, err := bridge.ParseHexColor(a.Color); err != nil {
+			errs = append(errs, newConfigError("color", "%s", err))
+		}
+	}
+
+	if a.RGB != nil {
+		if len(a.RGB) != 3 {
+			errs = append(errs, newConfigError("rgb", "must contain exactly three components"))
+		}
+
+		for index, value := range a.RGB {
+			if value < 0 || value > 255 {
+				errs = append(errs, newConfigError(fmt.Sprintf("rgb[%d]", index), "%d is out of range, must be between 0 and 255", value))
+			}
+		}
+	}
+
+	if a.XY != nil {
+		if len(a.XY) != 2 {
+			errs = append(errs, newConfigError("xy", "must contain exactly two coordinates"))
+		}
+
+		for index, value := range a.XY {
+			if value < 0 || value > 1 {
+				errs = append(errs, newConfigError(fmt.Sprintf("xy[%d]", index), "%g is out of range, must be between 0 and 1", value))
+			}
+		}
+	}
+
+	return joinInvalid(errs)
+}
+
+// color of the adjustment in CIE xy, must be called on a valid adjustment
+func (a adjustment) color() bridge.XY {
+	switch {
+	case len(a.Color) != 0:
+		red, green, blue, _ := bridge.ParseHexColor(a.Color)
+		return bridge.RGBToXY(red, green, blue)
+	case a.RGB != nil:
+		return bridge.RGBToXY(uint8(a.RGB[0]), uint8(a.RGB[1]), uint8(a.RGB[2]))
+	default:
+		return bridge.XY{a.XY[0], a.XY[1]}
+	}
+}
+
+// mirek of the adjustment, must be called on a valid adjustment with kelvin or mirek
+func (a adjustment) mirek() int {
+	if a.Kelvin != nil {
+		return bridge.KelvinToMirek(*a.Kelvin)
+	}
+
+	return *a.Mirek
+}
+
+// lightState converts adjustment to the state of given light, according to its capabilities
+func (a adjustment) lightState(light bridge.Light) bridge.State {
+	state := bridge.State{
+		On:             a.On,
+		TransitionTime: a.TransitionTime,
+	}
+
+	if state.On == nil && (a.Brightness != nil || a.Kelvin != nil || a.Mirek != nil || a.hasColor()) {
+		state.On = boolPointer(true)
+	}
+
+	if light.IsDimmable() {
+		if a.Brightness != nil {
+			state.Bri = intPointer(int(math.Max(1, math.Round(float64(*a.Brightness)*254/100))))
+		}
+
+		if a.BrightnessStep != nil {
+			state.BriInc = intPointer(int(math.Round(float64(*a.BrightnessStep) * 254 / 100)))
+		}
+	}
+
+	min, max := light.MirekRange()
+
+	switch {
+	case a.Kelvin != nil || a.Mirek != nil:
+		if light.HasCT() {
+			state.Ct = intPointer(clamp(a.mirek(), min, max))
+		}
+	case a.MirekStep != nil:
+		if light.HasCT() {
+			state.CtInc = a.MirekStep
+		}
+	case a.hasColor():
+		if light.HasColor() {
+			state.XY = lightXY(light, a.color())
+		} else if light.HasCT() {
+			state.Ct = intPointer(clamp(bridge.XYToMirek(a.color()), min, max))
+		}
+	}
+
+	return state
+}
+
+func (a adjustment) String() string {
+	if len(a.State) != 0 {
+		return a.State
+	}
+
+	if len(a.Scene) != 0 {
+		return "on scene"
+	}
+
+	var parts []string
+
+	if a.On != nil && !*a.On {
+		parts = append(parts, "off")
+	}
+
+	if a.Brightness != nil {
+		parts = append(parts, fmt.Sprintf("%d%%", *a.Brightness))
+	}
+
+	if a.BrightnessStep != nil {
+		if *a.BrightnessStep < 0 {
+			parts = append(parts, "dimmer")
+		} else {
+			parts = append(parts, "brighter")
+		}
+	}
+
+	switch {
+	case a.Kelvin != nil:
+		parts = append(parts, fmt.Sprintf("%dK", *a.Kelvin))
+	case a.Mirek != nil:
+		parts = append(parts, fmt.Sprintf("%d mirek", *a.Mirek))
+	case a.MirekStep != nil:
+		if *a.MirekStep < 0 {
+			parts = append(parts, "cooler")
+		} else {
+			parts = append(parts, "warmer")
+		}
+	case len(a.Color) != 0:
+		parts = append(parts, a.Color)
+	case a.RGB != nil:
+		parts = append(parts, fmt.Sprintf("rgb%v", a.RGB))
+	case a.XY != nil:
+		parts = append(parts, fmt.Sprintf("xy%v", a.XY))
+	}
+
+	if len(parts) == 0 {
+		return "on"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (a *app) adjustGroup(ctx context.Context, groupID string, adjust adjustment) (Group, error) {
+	if err := adjust.validate(); err != nil {
+		return Group{}, err
+	}
+
+	if len(adjust.State) != 0 {
+		return a.updateGroupState(ctx, groupID, adjust.State)
+	}
+
+	if len(adjust.Scene) != 0 {
+		return a.recallScene(ctx, groupID, adjust.Scene)
+	}
+
+	a.mutex.RLock()
+	group, ok := a.groups[groupID]
+	lights := make(map[string]bridge.Light, len(group.Lights))
+	for _, lightID := range group.Lights {
+		if light, ok := a.lights[lightID]; ok {
+			lights[lightID] = light
+		}
+	}
+	a.mutex.RUnlock()
+
+	if !ok {
+		return Group{}, model.WrapNotFound(fmt.Errorf("unknown group '%s'", groupID))
+	}
+
+	if !adjust.hasWhite() && !adjust.hasColor() {
+		if err := a.client.UpdateGroupAction(ctx, groupID, adjust.lightState(bridge.Light{})); err != nil {
+			return Group{}, wrapBridgeError(err)
+		}
+	} else {
+		for lightID, light := range lights {
+			if err := a.client.UpdateLightState(ctx, lightID, adjust.lightState(light)); err != nil {
+				return Group{}, wrapBridgeError(err)
+			}
+		}
+	}
+
+	a.overrideMotion(ctx, groupID)
+
+	if err := a.syncGroups(); err != nil {
+		return Group{}, wrapBridgeError(err)
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.groups[groupID], nil
+}
+
+func mirekToKelvin(mirek int) int {
+	if mirek == 0 {
+		return maxKelvin
+	}
+
+	return int(math.Round(1000000/float64(mirek)/100)) * 100
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
@@ -0,0 +1,82 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func TestAdjustmentLightState(t *testing.T) {
+	color := bridge.Light{Type: "Extended color light", Capabilities: bridge.LightCapabilities{Control: bridge.LightControl{ColorGamutType: "C", CT: &bridge.LightRangeCT{Min: 153, Max: 500}}}}
+	ambiance := bridge.Light{Type: "Color temperature light", Capabilities: bridge.LightCapabilities{Control: bridge.LightControl{CT: &bridge.LightRangeCT{Min: 153, Max: 454}}}}
+	plug := bridge.Light{Type: "On/Off plug-in unit"}
+
+	type args struct {
+		adjust adjustment
+		light  bridge.Light
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      bridge.State
+	}{
+		{
+			"brightness",
+			args{
+				adjust: adjustment{Brightness: intPointer(50), TransitionTime: intPointer(0)},
+				light:  color,
+			},
+			bridge.State{On: boolPointer(true), Bri: intPointer(127), TransitionTime: intPointer(0)},
+		},
+		{
+			"kelvin out of light range",
+			args{
+				adjust: adjustment{Kelvin: intPointer(2000)},
+				light:  ambiance,
+			},
+			bridge.State{On: boolPointer(true), Ct: intPointer(454)},
+		},
+		{
+			"color in gamut",
+			args{
+				adjust: adjustment{Color: "#ff0000"},
+				light:  color,
+			},
+			bridge.State{On: boolPointer(true), XY: []float64{0.6915, 0.3083}},
+		},
+		{
+			"color without color capability",
+			args{
+				adjust: adjustment{Color: "#ff0000"},
+				light:  ambiance,
+			},
+			bridge.State{On: boolPointer(true), Ct: intPointer(454)},
+		},
+		{
+			"steps",
+			args{
+				adjust: adjustment{BrightnessStep: intPointer(-10)},
+				light:  color,
+			},
+			bridge.State{BriInc: intPointer(-25)},
+		},
+		{
+			"plug",
+			args{
+				adjust: adjustment{Brightness: intPointer(50), Color: "#ff0000"},
+				light:  plug,
+			},
+			bridge.State{On: boolPointer(true)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := tc.args.adjust.lightState(tc.args.light); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("lightState() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
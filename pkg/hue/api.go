@@ -1,86 +1,914 @@
 package hue
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
+	_ "embed" // for embedding OpenAPI description
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/ViBiOh/httputils/v4/pkg/httperror"
 	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
-	"github.com/ViBiOh/httputils/v4/pkg/request"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
-func hasError(content []byte) bool {
-	return !bytes.Contains(content, []byte("success"))
+const (
+	v1Path      = "/v1"
+	statesPath  = "/states"
+	openAPIPath = "/openapi.yaml"
+)
+
+//go:embed openapi.yaml
+var openAPI []byte
+
+type apiError struct {
+	Error string `json:"error"`
 }
 
-func get(ctx context.Context, url string, response interface{}) error {
-	resp, err := request.New().Get(url).Send(ctx, nil)
-	if err != nil {
-		return err
+type apiGroup struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Lights []string `json:"lights"`
+	Scene  string   `json:"scene,omitempty"`
+	On     bool     `json:"on"`
+	AllOn  bool     `json:"allOn"`
+	Tap    bool     `json:"tap"`
+}
+
+type apiLight struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Model        string    `json:"model"`
+	Product      string    `json:"product,omitempty"`
+	ColorMode    string    `json:"colorMode,omitempty"`
+	XY           []float64 `json:"xy,omitempty"`
+	Bri          int       `json:"bri,omitempty"`
+	Brightness   int       `json:"brightness"`
+	Ct           int       `json:"ct,omitempty"`
+	Hue          int       `json:"hue,omitempty"`
+	Sat          int       `json:"sat,omitempty"`
+	On           bool      `json:"on"`
+	Reachable    bool      `json:"reachable"`
+	Dimmable     bool      `json:"dimmable"`
+	ColorCapable bool      `json:"color"`
+	CTCapable    bool      `json:"colorTemperature"`
+}
+
+type apiScene struct {
+	Lightstates map[string]bridge.State `json:"lightstates,omitempty"`
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Group       string                  `json:"group,omitempty"`
+	Lights      []string                `json:"lights"`
+	Managed     bool                    `json:"managed"`
+	Active      bool                    `json:"active,omitempty"`
+}
+
+type sceneRequest struct {
+	Name    string `json:"name"`
+	Capture bool   `json:"capture"`
+}
+
+type apiSchedule struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Status    string         `json:"status"`
+	Localtime string         `json:"localtime"`
+	Group     string         `json:"group,omitempty"`
+	State     string         `json:"state"`
+	Next      *time.Time     `json:"next,omitempty"`
+	Solar     *solarSchedule `json:"solar,omitempty"`
+	When      string         `json:"when"`
+	Scene     string         `json:"scene,omitempty"`
+	Managed   bool           `json:"managed"`
+}
+
+type apiVacation struct {
+	Start    string           `json:"start"`
+	End      string           `json:"end"`
+	State    string           `json:"state"`
+	Groups   []string         `json:"groups"`
+	Windows  []vacationWindow `json:"windows"`
+	Switches []apiSchedule    `json:"switches"`
+	Ongoing  bool             `json:"ongoing"`
+}
+
+type apiRule struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Owner          string   `json:"owner"`
+	Status         string   `json:"status"`
+	LastTriggered  string   `json:"lastTriggered"`
+	Description    string   `json:"description"`
+	Conditions     []string `json:"conditions"`
+	Actions        []string `json:"actions"`
+	TimesTriggered int      `json:"timesTriggered"`
+	Managed        bool     `json:"managed"`
+}
+
+type apiSensor struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	On            bool    `json:"on"`
+	Presence      bool    `json:"presence"`
+	Temperature   float32 `json:"temperature"`
+	Battery       uint    `json:"battery"`
+	LedIndication bool    `json:"ledIndication"`
+}
+
+type sensorRequest struct {
+	On *bool `json:"on"`
+}
+
+func (a *app) toAPIGroup(id string, group Group) apiGroup {
+	return apiGroup{
+		Scene:  a.activeScene(id),
+		ID:     id,
+		Name:   group.Name,
+		Type:   group.Type,
+		Lights: group.Lights,
+		On:     group.State.AnyOn,
+		AllOn:  group.State.AllOn,
+		Tap:    group.Tap,
 	}
+}
 
-	if err := httpjson.Read(resp, &response); err != nil {
-		return fmt.Errorf("unable to read hue content: %s", err)
+func toAPILight(id string, light bridge.Light) apiLight {
+	return apiLight{
+		ID:           id,
+		Name:         light.Name,
+		Type:         light.Type,
+		Model:        light.ModelID,
+		Product:      light.ProductName,
+		ColorMode:    light.State.ColorMode,
+		XY:           light.State.XY,
+		Bri:          light.State.Bri,
+		Brightness:   brightnessPercent(light.State.Bri),
+		Ct:           light.State.Ct,
+		Hue:          light.State.Hue,
+		Sat:          light.State.Sat,
+		On:           light.State.On,
+		Reachable:    light.State.Reachable,
+		Dimmable:     light.IsDimmable(),
+		ColorCapable: light.HasColor(),
+		CTCapable:    light.HasCT(),
 	}
-	return nil
 }
 
-func create(ctx context.Context, url string, payload interface{}) (string, error) {
-	resp, err := request.New().Post(url).JSON(ctx, payload)
-	if err != nil {
-		return "", err
+func toAPIScene(scene bridge.Scene) apiScene {
+	return apiScene{
+		Lightstates: scene.Lightstates,
+		ID:          scene.ID,
+		Name:        scene.Name,
+		Group:       scene.Group,
+		Lights:      scene.Lights,
+		Managed:     isManagedScene(scene),
 	}
+}
 
-	content, err := request.ReadBodyResponse(resp)
-	if err != nil {
-		return "", err
+func (a *app) toAPISchedule(schedule bridge.Schedule) apiSchedule {
+	output := apiSchedule{
+		ID:        schedule.ID,
+		Name:      schedule.Name,
+		Status:    schedule.Status,
+		Localtime: schedule.Localtime,
+		When:      schedule.FormatLocalTime(),
+		Group:     schedule.Command.GetGroup(),
+		State:     findStateName(schedule, a.scenes, a.states),
+		Scene:     newScheduleRequest(schedule).Scene,
+		Managed:   isManagedSchedule(schedule),
 	}
 
-	if hasError(content) {
-		return "", fmt.Errorf("create error: %s", content)
+	now := a.now()
+
+	if pattern, err := bridge.ParseTimePattern(schedule.Localtime); err == nil {
+		if next, ok := pattern.Next(now); ok {
+			output.Next = &next
+		}
 	}
 
-	var response []map[string]map[string]string
-	if err := json.Unmarshal(content, &response); err != nil {
-		return "", err
+	if solar, ok := a.solarSchedule(schedule, now); ok {
+		output.Solar = &solar
 	}
 
-	return response[0]["success"]["id"], nil
+	return output
 }
 
-func update(ctx context.Context, url string, payload interface{}) error {
-	resp, err := request.New().Put(url).JSON(ctx, payload)
-	if err != nil {
-		return err
+// toAPIVacation describes the vacation and its programmed switches. Caller must hold the read lock
+func (a *app) toAPIVacation(value vacation) apiVacation {
+	output := apiVacation{
+		Start:    value.Start.Format(dayFormat),
+		End:      value.End.Format(dayFormat),
+		State:    value.State,
+		Groups:   value.Groups,
+		Windows:  value.Windows,
+		Switches: make([]apiSchedule, 0),
+		Ongoing:  value.Ongoing,
 	}
 
-	content, err := request.ReadBodyResponse(resp)
-	if err != nil {
-		return err
+	for _, schedule := range a.vacationSchedules() {
+		output.Switches = append(output.Switches, a.toAPISchedule(schedule))
 	}
 
-	if hasError(content) {
-		return fmt.Errorf("update error: %s", content)
+	return output
+}
+
+func toAPISensor(sensor bridge.Sensor) apiSensor {
+	return apiSensor{
+		ID:            sensor.ID,
+		Name:          sensor.Name,
+		On:            sensor.Config.On,
+		Presence:      sensor.State.Presence,
+		Temperature:   sensor.State.Temperature,
+		Battery:       sensor.Config.Battery,
+		LedIndication: sensor.Config.LedIndication,
 	}
+}
 
-	return nil
+func toAPIRule(rule ruleDescription) apiRule {
+	return apiRule{
+		ID:             rule.ID,
+		Name:           rule.Name,
+		Owner:          rule.Owner,
+		Status:         rule.Status,
+		LastTriggered:  rule.LastTriggered,
+		Description:    rule.String(),
+		Conditions:     rule.Conditions,
+		Actions:        rule.Actions,
+		TimesTriggered: rule.TimesTriggered,
+		Managed:        rule.Managed,
+	}
 }
 
-func remove(ctx context.Context, url string) error {
-	resp, err := request.New().Delete(url).Send(ctx, nil)
-	if err != nil {
-		return err
+func (a *app) handleV1(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, v1Path)
+
+	if path == openAPIPath {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(openAPI); err != nil {
+			logger.Error("unable to write OpenAPI description: %s", err)
+		}
+		return
+	}
+
+	resource, id := path, ""
+	if parts := strings.SplitN(strings.Trim(path, "/"), "/", 2); len(parts) == 2 {
+		resource, id = "/"+parts[0], parts[1]
+	}
+
+	switch resource {
+	case groupsPath:
+		a.handleV1Groups(w, r, id)
+	case lightsPath:
+		a.handleV1Lights(w, r, id)
+	case scenesPath:
+		a.handleV1Scenes(w, r, id)
+	case schedulesPath:
+		a.handleV1Schedules(w, r, id)
+	case sensorsPath:
+		a.handleV1Sensors(w, r, id)
+	case statesPath:
+		a.handleV1States(w, r, id)
+	case vacationPath:
+		a.handleV1Vacation(w, r)
+	case rulesPath:
+		a.handleV1Rules(w, r, id)
+	default:
+		writeAPIError(w, r, model.WrapNotFound(fmt.Errorf("unknown path `%s`", r.URL.Path)))
+	}
+}
+
+func (a *app) handleV1Groups(w http.ResponseWriter, r *http.Request, id string) {
+	if strings.HasSuffix(id, "/state") {
+		if r.Method != http.MethodPut {
+			writeMethodNotAllowed(w, http.MethodPut)
+			return
+		}
+
+		var payload adjustment
+		if err := httpjson.Parse(r, &payload); err != nil {
+			writeAPIError(w, r, model.WrapInvalid(err))
+			return
+		}
+
+		group, err := a.adjustGroup(r.Context(), strings.TrimSuffix(id, "/state"), payload)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		a.mutex.RLock()
+		output := a.toAPIGroup(strings.TrimSuffix(id, "/state"), group)
+		a.mutex.RUnlock()
+
+		httpjson.Write(w, http.StatusOK, output, httpjson.IsPretty(r))
+		return
+	}
+
+	if strings.HasSuffix(id, scenesPath) && r.Method == http.MethodPost {
+		var payload sceneRequest
+		if err := httpjson.Parse(r, &payload); err != nil {
+			writeAPIError(w, r, model.WrapInvalid(err))
+			return
+		}
+
+		scene, err := a.createScene(r.Context(), strings.TrimSuffix(id, scenesPath), payload.Name)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusCreated, toAPIScene(scene), httpjson.IsPretty(r))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		if strings.HasSuffix(id, scenesPath) {
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+		} else {
+			writeMethodNotAllowed(w, http.MethodGet)
+		}
+		return
 	}
 
-	content, err := request.ReadBodyResponse(resp)
+	if strings.HasSuffix(id, scenesPath) {
+		items, err := a.readAPIGroupScenes(strings.TrimSuffix(id, scenesPath))
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.WriteArray(w, http.StatusOK, items, httpjson.IsPretty(r))
+		return
+	}
+
+	if len(id) != 0 {
+		item, err := a.readAPIGroup(id)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, item, httpjson.IsPretty(r))
+		return
+	}
+
+	httpjson.WriteArray(w, http.StatusOK, a.readAPIGroups(), httpjson.IsPretty(r))
+}
+
+// readAPIGroupScenes builds the payload under read lock, the response is written once it's released for not blocking updates with a slow client
+func (a *app) readAPIGroupScenes(groupID string) ([]apiScene, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if _, ok := a.groups[groupID]; !ok {
+		return nil, model.WrapNotFound(fmt.Errorf("unknown group '%s'", groupID))
+	}
+
+	active := a.activeScene(groupID)
+	scenes := a.groupScenes(groupID)
+
+	items := make([]apiScene, len(scenes))
+	for index, scene := range scenes {
+		items[index] = toAPIScene(scene)
+		items[index].Active = scene.ID == active
+	}
+
+	return items, nil
+}
+
+func (a *app) readAPIGroup(id string) (apiGroup, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	group, ok := a.groups[id]
+	if !ok {
+		return apiGroup{}, model.WrapNotFound(fmt.Errorf("unknown group '%s'", id))
+	}
+
+	return a.toAPIGroup(id, group), nil
+}
+
+func (a *app) readAPIGroups() []apiGroup {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	keys := make([]string, 0, len(a.groups))
+	for key := range a.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]apiGroup, len(keys))
+	for index, key := range keys {
+		items[index] = a.toAPIGroup(key, a.groups[key])
+	}
+
+	return items
+}
+
+func (a *app) handleV1Lights(w http.ResponseWriter, r *http.Request, id string) {
+	if len(id) != 0 && r.Method == http.MethodPatch {
+		var payload configState
+		if err := httpjson.Parse(r, &payload); err != nil {
+			writeAPIError(w, r, model.WrapInvalid(err))
+			return
+		}
+
+		light, err := a.updateLightState(r.Context(), id, payload)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, toAPILight(id, light), httpjson.IsPretty(r))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		if len(id) != 0 {
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch)
+		} else {
+			writeMethodNotAllowed(w, http.MethodGet)
+		}
+		return
+	}
+
+	if len(id) != 0 {
+		item, err := a.readAPILight(id)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, item, httpjson.IsPretty(r))
+		return
+	}
+
+	httpjson.WriteArray(w, http.StatusOK, a.readAPILights(), httpjson.IsPretty(r))
+}
+
+func (a *app) readAPILight(id string) (apiLight, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	light, ok := a.lights[id]
+	if !ok {
+		return apiLight{}, model.WrapNotFound(fmt.Errorf("unknown light '%s'", id))
+	}
+
+	return toAPILight(id, light), nil
+}
+
+func (a *app) readAPILights() []apiLight {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	keys := make([]string, 0, len(a.lights))
+	for key := range a.lights {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]apiLight, len(keys))
+	for index, key := range keys {
+		items[index] = toAPILight(key, a.lights[key])
+	}
+
+	return items
+}
+
+func (a *app) handleV1Scenes(w http.ResponseWriter, r *http.Request, id string) {
+	if parts := strings.Split(id, "/"); len(parts) == 3 && parts[1] == "lightstates" {
+		if r.Method != http.MethodPut {
+			writeMethodNotAllowed(w, http.MethodPut)
+			return
+		}
+
+		var payload adjustment
+		if err := httpjson.Parse(r, &payload); err != nil {
+			writeAPIError(w, r, model.WrapInvalid(err))
+			return
+		}
+
+		scene, err := a.updateSceneLightState(r.Context(), parts[0], parts[2], payload)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, toAPIScene(scene), httpjson.IsPretty(r))
+		return
+	}
+
+	if len(id) != 0 {
+		switch r.Method {
+		case http.MethodPatch:
+			var payload sceneRequest
+			if err := httpjson.Parse(r, &payload); err != nil {
+				writeAPIError(w, r, model.WrapInvalid(err))
+				return
+			}
+
+			var scene bridge.Scene
+			var err error
+
+			if payload.Capture {
+				scene, err = a.captureScene(r.Context(), id)
+			}
+
+			if err == nil && len(payload.Name) != 0 {
+				scene, err = a.renameScene(r.Context(), id, payload.Name)
+			}
+
+			if err == nil && !payload.Capture && len(payload.Name) == 0 {
+				err = model.WrapInvalid(errors.New("name or capture is required"))
+			}
+
+			if err != nil {
+				writeAPIError(w, r, err)
+				return
+			}
+
+			httpjson.Write(w, http.StatusOK, toAPIScene(scene), httpjson.IsPretty(r))
+			return
+
+		case http.MethodDelete:
+			if _, err := a.deleteScene(r.Context(), id); err != nil {
+				writeAPIError(w, r, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	if r.Method != http.MethodGet {
+		if len(id) != 0 {
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+		} else {
+			writeMethodNotAllowed(w, http.MethodGet)
+		}
+		return
+	}
+
+	if len(id) != 0 {
+		item, err := a.readAPIScene(id)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, item, httpjson.IsPretty(r))
+		return
+	}
+
+	httpjson.WriteArray(w, http.StatusOK, a.readAPIScenes(), httpjson.IsPretty(r))
+}
+
+func (a *app) readAPIScene(id string) (apiScene, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	scene, ok := a.scenes[id]
+	if !ok {
+		return apiScene{}, model.WrapNotFound(fmt.Errorf("unknown scene '%s'", id))
+	}
+
+	return toAPIScene(scene), nil
+}
+
+func (a *app) readAPIScenes() []apiScene {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	keys := make([]string, 0, len(a.scenes))
+	for key := range a.scenes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]apiScene, len(keys))
+	for index, key := range keys {
+		items[index] = toAPIScene(a.scenes[key])
+	}
+
+	return items
+}
+
+func (a *app) handleV1Schedules(w http.ResponseWriter, r *http.Request, id string) {
+	if len(id) == 0 && r.Method == http.MethodPost {
+		var payload scheduleRequest
+		if err := httpjson.Parse(r, &payload); err != nil {
+			writeAPIError(w, r, model.WrapInvalid(err))
+			return
+		}
+
+		schedule, err := a.createSchedule(r.Context(), payload)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		a.mutex.RLock()
+		output := a.toAPISchedule(schedule)
+		a.mutex.RUnlock()
+
+		httpjson.Write(w, http.StatusCreated, output, httpjson.IsPretty(r))
+		return
+	}
+
+	if len(id) != 0 {
+		switch r.Method {
+		case http.MethodPatch, http.MethodPut:
+			var payload scheduleRequest
+			if err := httpjson.Parse(r, &payload); err != nil {
+				writeAPIError(w, r, model.WrapInvalid(err))
+				return
+			}
+
+			var schedule bridge.Schedule
+			var err error
+
+			if r.Method == http.MethodPatch {
+				schedule, err = a.updateScheduleStatus(r.Context(), id, payload.Status)
+			} else {
+				schedule, err = a.updateSchedule(r.Context(), id, payload)
+			}
+
+			if err != nil {
+				writeAPIError(w, r, err)
+				return
+			}
+
+			a.mutex.RLock()
+			output := a.toAPISchedule(schedule)
+			a.mutex.RUnlock()
+
+			httpjson.Write(w, http.StatusOK, output, httpjson.IsPretty(r))
+			return
+
+		case http.MethodDelete:
+			if _, err := a.deleteSchedule(r.Context(), id); err != nil {
+				writeAPIError(w, r, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	if r.Method != http.MethodGet {
+		if len(id) != 0 {
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodPut, http.MethodDelete)
+		} else {
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	if len(id) != 0 {
+		item, err := a.readAPISchedule(id)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, item, httpjson.IsPretty(r))
+		return
+	}
+
+	httpjson.WriteArray(w, http.StatusOK, a.readAPISchedules(), httpjson.IsPretty(r))
+}
+
+func (a *app) readAPISchedule(id string) (apiSchedule, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	schedule, ok := a.schedules[id]
+	if !ok {
+		return apiSchedule{}, model.WrapNotFound(fmt.Errorf("unknown schedule '%s'", id))
+	}
+
+	return a.toAPISchedule(schedule), nil
+}
+
+func (a *app) readAPISchedules() []apiSchedule {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	keys := make([]string, 0, len(a.schedules))
+	for key := range a.schedules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]apiSchedule, len(keys))
+	for index, key := range keys {
+		items[index] = a.toAPISchedule(a.schedules[key])
+	}
+
+	return items
+}
+
+func (a *app) handleV1Sensors(w http.ResponseWriter, r *http.Request, id string) {
+	if len(id) != 0 && r.Method == http.MethodPatch {
+		var payload sensorRequest
+		if err := httpjson.Parse(r, &payload); err != nil {
+			writeAPIError(w, r, model.WrapInvalid(err))
+			return
+		}
+
+		if payload.On == nil {
+			writeAPIError(w, r, model.WrapInvalid(errors.New("on is required")))
+			return
+		}
+
+		sensor, err := a.updateSensorOn(r.Context(), id, *payload.On)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, toAPISensor(sensor), httpjson.IsPretty(r))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		if len(id) != 0 {
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch)
+		} else {
+			writeMethodNotAllowed(w, http.MethodGet)
+		}
+		return
+	}
+
+	items := a.readAPISensors()
+
+	if len(id) == 0 {
+		httpjson.WriteArray(w, http.StatusOK, items, httpjson.IsPretty(r))
+		return
+	}
+
+	for _, item := range items {
+		if item.ID == id {
+			httpjson.Write(w, http.StatusOK, item, httpjson.IsPretty(r))
+			return
+		}
+	}
+
+	writeAPIError(w, r, model.WrapNotFound(fmt.Errorf("unknown sensor '%s'", id)))
+}
+
+func (a *app) readAPISensors() []apiSensor {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	items := make([]apiSensor, 0, len(a.sensors))
+	for _, sensor := range a.sensors {
+		items = append(items, toAPISensor(sensor))
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
+
+func (a *app) handleV1States(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	if len(id) != 0 {
+		writeAPIError(w, r, model.WrapNotFound(fmt.Errorf("unknown path `%s`", r.URL.Path)))
+		return
+	}
+
+	a.mutex.RLock()
+	names := make([]string, 0, len(a.states))
+	for name := range a.states {
+		names = append(names, name)
+	}
+	a.mutex.RUnlock()
+
+	sort.Strings(names)
+
+	httpjson.WriteArray(w, http.StatusOK, names, httpjson.IsPretty(r))
+}
+
+func (a *app) handleV1Rules(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	rules, err := a.listRules(r.Context())
 	if err != nil {
-		return err
+		writeAPIError(w, r, err)
+		return
+	}
+
+	items := make([]apiRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(id) != 0 && rule.ID == id {
+			httpjson.Write(w, http.StatusOK, toAPIRule(rule), httpjson.IsPretty(r))
+			return
+		}
+
+		items = append(items, toAPIRule(rule))
+	}
+
+	if len(id) != 0 {
+		writeAPIError(w, r, model.WrapNotFound(fmt.Errorf("unknown rule '%s'", id)))
+		return
+	}
+
+	httpjson.WriteArray(w, http.StatusOK, items, httpjson.IsPretty(r))
+}
+
+func (a *app) handleV1Vacation(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.mutex.RLock()
+		current := a.vacation
+		var output apiVacation
+		if current != nil {
+			output = a.toAPIVacation(*current)
+		}
+		a.mutex.RUnlock()
+
+		if current == nil {
+			writeAPIError(w, r, model.WrapNotFound(errors.New("no vacation set")))
+			return
+		}
+
+		httpjson.Write(w, http.StatusOK, output, httpjson.IsPretty(r))
+
+	case http.MethodPut:
+		var payload vacationRequest
+		if err := httpjson.Parse(r, &payload); err != nil {
+			writeAPIError(w, r, model.WrapInvalid(err))
+			return
+		}
+
+		output, err := a.startVacation(r.Context(), payload)
+		if err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		a.mutex.RLock()
+		item := a.toAPIVacation(output)
+		a.mutex.RUnlock()
+
+		httpjson.Write(w, http.StatusCreated, item, httpjson.IsPretty(r))
+
+	case http.MethodDelete:
+		if err := a.stopVacation(r.Context()); err != nil {
+			writeAPIError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	httpjson.Write(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed"}, false)
+}
+
+func writeAPIError(w http.ResponseWriter, r *http.Request, err error) {
+	status, message := httperror.ErrorStatus(err)
+	if errors.Is(err, bridge.ErrUnauthorized) {
+		status, message = http.StatusBadGateway, err.Error()
 	}
 
-	if hasError(content) {
-		return fmt.Errorf("remove error: %s", content)
+	if status >= http.StatusInternalServerError {
+		logger.Error("HTTP/%d: %s", status, err)
+	} else {
+		logger.Warn("HTTP/%d: %s", status, err)
 	}
 
-	return nil
+	httpjson.Write(w, status, apiError{Error: message}, httpjson.IsPretty(r))
 }
@@ -0,0 +1,148 @@
+package hue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func TestHandleV1(t *testing.T) {
+	_, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "secret"),
+		states:         States,
+	}
+
+	if err := instance.syncGroups(); err != nil {
+		t.Fatalf("syncGroups() error = %s", err)
+	}
+
+	if err := instance.syncSensors(); err != nil {
+		t.Fatalf("syncSensors() error = %s", err)
+	}
+
+	type args struct {
+		method string
+		path   string
+		body   string
+	}
+
+	var cases = []struct {
+		intention  string
+		args       args
+		wantStatus int
+		want       string
+	}{
+		{
+			"list groups",
+			args{
+				method: http.MethodGet,
+				path:   "/v1/groups",
+			},
+			http.StatusOK,
+			`{"id":"2","name":"Bedroom","type":"Room","lights":["3"],"on":false,"allOn":false,"tap":false}`,
+		},
+		{
+			"set group state",
+			args{
+				method: http.MethodPut,
+				path:   "/v1/groups/2/state",
+				body:   `{"state":"on"}`,
+			},
+			http.StatusOK,
+			`{"id":"2","name":"Bedroom","type":"Room","lights":["3"],"on":true,"allOn":true,"tap":false}`,
+		},
+		{
+			"unknown state",
+			args{
+				method: http.MethodPut,
+				path:   "/v1/groups/2/state",
+				body:   `{"state":"sunrise"}`,
+			},
+			http.StatusBadRequest,
+			`{"error":"unknown state 'sunrise': invalid"}`,
+		},
+		{
+			"unknown group",
+			args{
+				method: http.MethodGet,
+				path:   "/v1/groups/42",
+			},
+			http.StatusNotFound,
+			`{"error":"unknown group '42': not found"}`,
+		},
+		{
+			"toggle sensor",
+			args{
+				method: http.MethodPatch,
+				path:   "/v1/sensors/5",
+				body:   `{"on":false}`,
+			},
+			http.StatusOK,
+			`"on":false`,
+		},
+		{
+			"method not allowed",
+			args{
+				method: http.MethodDelete,
+				path:   "/v1/sensors",
+			},
+			http.StatusMethodNotAllowed,
+			`{"error":"method not allowed"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+			instance.Handler().ServeHTTP(writer, httptest.NewRequest(tc.args.method, tc.args.path, strings.NewReader(tc.args.body)))
+
+			if got := writer.Code; got != tc.wantStatus {
+				t.Errorf("Handler() = %d, want %d", got, tc.wantStatus)
+			}
+
+			if got := writer.Body.String(); !strings.Contains(got, tc.want) {
+				t.Errorf("Handler() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandlerNotConnected(t *testing.T) {
+	writer := httptest.NewRecorder()
+	(&app{}).Handler().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/v1/groups", nil))
+
+	if got := writer.Code; got != http.StatusServiceUnavailable {
+		t.Errorf("Handler() = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHandleV1Unauthorized(t *testing.T) {
+	_, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "unknown"),
+		states:         States,
+		groups:         map[string]Group{"2": {Group: bridge.Group{Name: "Bedroom", Lights: []string{"3"}}}},
+	}
+
+	writer := httptest.NewRecorder()
+	instance.Handler().ServeHTTP(writer, httptest.NewRequest(http.MethodPut, "/v1/groups/2/state", strings.NewReader(`{"state":"on"}`)))
+
+	if got := writer.Code; got != http.StatusBadGateway {
+		t.Errorf("Handler() = %d, want %d", got, http.StatusBadGateway)
+	}
+
+	if got, want := writer.Body.String(), "pair again with `hue pair`"; !strings.Contains(got, want) {
+		t.Errorf("Handler() = `%s`, want `%s`", got, want)
+	}
+}
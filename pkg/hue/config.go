@@ -0,0 +1,604 @@
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	allLightsGroup = "0"
+)
+
+var (
+	timeRegex     = `\d{2}:\d{2}:\d{2}`
+	durationRegex = regexp.MustCompile(fmt.Sprintf(`^PT%s$`, timeRegex))
+)
+
+type configError struct {
+	path    string
+	message string
+}
+
+func (e configError) Error() string {
+	return fmt.Sprintf("%s: %s", e.path, e.message)
+}
+
+type configErrors []error
+
+func (e configErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = fmt.Sprintf("- %s", err)
+	}
+
+	return fmt.Sprintf("invalid configuration:\n%s", strings.Join(messages, "\n"))
+}
+
+func newConfigError(path, format string, a ...interface{}) error {
+	return configError{
+		path:    path,
+		message: fmt.Sprintf(format, a...),
+	}
+}
+
+func parseConfig(content []byte) (*configHue, error) {
+	var raw interface{}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("unable to parse configuration: %s", err)
+	}
+
+	errs := checkUnknownFields(raw, reflect.TypeOf(configHue{}), "")
+	if len(errs) > 0 {
+		return nil, configErrors(errs)
+	}
+
+	var config configHue
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse configuration: %s", err)
+	}
+
+	if errs := config.validate(); len(errs) > 0 {
+		return nil, configErrors(errs)
+	}
+
+	return &config, nil
+}
+
+func checkUnknownFields(value interface{}, kind reflect.Type, path string) []error {
+	var errs []error
+
+	switch kind.Kind() {
+	case reflect.Ptr:
+		return checkUnknownFields(value, kind.Elem(), path)
+
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		for _, key := range sortedKeys(mapKeys(object)) {
+			field, ok := findJSONField(kind, key)
+			if !ok {
+				errs = append(errs, newConfigError(joinPath(path, key), "unknown field"))
+				continue
+			}
+
+			errs = append(errs, checkUnknownFields(object[key], field.Type, joinPath(path, key))...)
+		}
+
+	case reflect.Slice:
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		for index, item := range array {
+			errs = append(errs, checkUnknownFields(item, kind.Elem(), fmt.Sprintf("%s[%d]", path, index))...)
+		}
+
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		for _, key := range sortedKeys(mapKeys(object)) {
+			errs = append(errs, checkUnknownFields(object[key], kind.Elem(), joinPath(path, key))...)
+		}
+	}
+
+	return errs
+}
+
+func findJSONField(kind reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func mapKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func (c configHue) validate() []error {
+	var errs []error
+
+	if c.Location != nil {
+		if c.Location.Latitude < -90 || c.Location.Latitude > 90 {
+			errs = append(errs, newConfigError("location.latitude", "latitude must be between -90 and 90"))
+		}
+
+		if c.Location.Longitude < -180 || c.Location.Longitude > 180 {
+			errs = append(errs, newConfigError("location.longitude", "longitude must be between -180 and 180"))
+		}
+	}
+
+	for _, name := range sortedKeys(stateKeys(c.States)) {
+		if _, ok := States[name]; ok {
+			errs = append(errs, newConfigError(fmt.Sprintf("states.%s", name), "`%s` is a built-in state and can't be redefined", name))
+			continue
+		}
+
+		errs = append(errs, c.States[name].validate(fmt.Sprintf("states.%s", name))...)
+	}
+
+	states := c.lightStates()
+
+	scheduleNames := make(map[string]bool)
+	for index, schedule := range c.Schedules {
+		path := fmt.Sprintf("schedules[%d]", index)
+
+		if len(strings.TrimSpace(schedule.Name)) == 0 {
+			errs = append(errs, newConfigError(joinPath(path, "name"), "name is required"))
+		} else if scheduleNames[schedule.Name] {
+			errs = append(errs, newConfigError(joinPath(path, "name"), "duplicate schedule name `%s`", schedule.Name))
+		}
+		scheduleNames[schedule.Name] = true
+
+		if isSolarTime(schedule.Localtime) {
+			if _, err := parseSolarTime(schedule.Localtime); err != nil {
+				errs = append(errs, newConfigError(joinPath(path, "localtime"), "%s", err))
+			} else if c.Location == nil {
+				errs = append(errs, newConfigError(joinPath(path, "localtime"), "location is required for solar time `%s`", schedule.Localtime))
+			}
+		} else if _, err := bridge.ParseTimePattern(schedule.Localtime); err != nil {
+			errs = append(errs, newConfigError(joinPath(path, "localtime"), "malformed time pattern `%s`, e.g. `W124/T07:55:00` or `sunset - 30m`", schedule.Localtime))
+		}
+
+		if len(schedule.Group) == 0 {
+			errs = append(errs, newConfigError(joinPath(path, "group"), "group is required"))
+		}
+
+		errs = append(errs, validateState(joinPath(path, "state"), schedule.State, states)...)
+	}
+
+	sensorRuleNames := make(map[string]bool)
+	for index, sensor := range c.Sensors {
+		path := fmt.Sprintf("sensors[%d]", index)
+
+		if len(sensor.ID) == 0 {
+			errs = append(errs, newConfigError(joinPath(path, "id"), "id is required"))
+		}
+
+		if len(sensor.LightSensorID) == 0 {
+			errs = append(errs, newConfigError(joinPath(path, "lightSensorId"), "lightSensorId is required"))
+		}
+
+		if !durationRegex.MatchString(sensor.OffDelay) {
+			errs = append(errs, newConfigError(joinPath(path, "offDelay"), "malformed duration `%s`, e.g. `PT00:01:00`", sensor.OffDelay))
+		}
+
+		if len(sensor.Groups) == 0 {
+			errs = append(errs, newConfigError(joinPath(path, "groups"), "at least one group is required"))
+		}
+
+		if sensor.LightLevel != nil && (*sensor.LightLevel < 0 || *sensor.LightLevel > 65535) {
+			errs = append(errs, newConfigError(joinPath(path, "lightlevel"), "%d is out of range, must be between 0 and 65535", *sensor.LightLevel))
+		}
+
+		if len(sensor.OffState) != 0 {
+			errs = append(errs, validateState(joinPath(path, "offState"), sensor.OffState, states)...)
+		}
+
+		errs = append(errs, validateBands(path, sensor.Bands, states)...)
+
+		// off rule has the most actions, like the on ones: one per group and one for the companion
+		ruleNames := sensor.ruleNames()
+		errs = append(errs, validateRuleLimits(path, ruleNames[0], sensor.Groups, []configSensor{sensor}, false)...)
+
+		for _, name := range ruleNames {
+			if name != ruleNames[0] {
+				errs = append(errs, validateRuleLimits(path, name, nil, nil, false)...)
+			}
+
+			if sensorRuleNames[name] {
+				errs = append(errs, newConfigError(path, "rule name `%s` is generated twice, offState can't be named like a band or a rule of another sensor", name))
+			}
+			sensorRuleNames[name] = true
+		}
+	}
+
+	for index, tap := range c.Taps {
+		path := fmt.Sprintf("taps[%d]", index)
+
+		if len(tap.ID) == 0 {
+			errs = append(errs, newConfigError(joinPath(path, "id"), "id is required"))
+		}
+
+		buttonIDs := make(map[string]bool)
+		for buttonIndex, button := range tap.Buttons {
+			buttonPath := fmt.Sprintf("%s.buttons[%d]", path, buttonIndex)
+
+			if _, ok := tapButtonMapping[button.ID]; !ok {
+				errs = append(errs, newConfigError(joinPath(buttonPath, "id"), "unknown button `%s`, must be between 1 and 4", button.ID))
+			} else if buttonIDs[button.ID] {
+				errs = append(errs, newConfigError(joinPath(buttonPath, "id"), "duplicate button `%s`", button.ID))
+			}
+			buttonIDs[button.ID] = true
+
+			actions := 0
+			for _, set := range []bool{len(button.State) != 0, len(button.States) != 0, len(button.Scenes) != 0} {
+				if set {
+					actions++
+				}
+			}
+
+			switch {
+			case button.Toggle && (len(button.States) != 0 || len(button.Scenes) != 0):
+				errs = append(errs, newConfigError(buttonPath, "toggle can only be combined with state"))
+			case !button.Toggle && actions == 0:
+				errs = append(errs, newConfigError(buttonPath, "one of state, states, scenes or toggle is required"))
+			case actions > 1:
+				errs = append(errs, newConfigError(buttonPath, "state, states and scenes can't be combined"))
+			}
+
+			if len(button.State) != 0 {
+				errs = append(errs, validateState(joinPath(buttonPath, "state"), button.State, states)...)
+			}
+
+			for stateIndex, state := range button.States {
+				errs = append(errs, validateState(fmt.Sprintf("%s.states[%d]", buttonPath, stateIndex), state, states)...)
+			}
+
+			for sceneIndex, scene := range button.Scenes {
+				if len(scene) == 0 {
+					errs = append(errs, newConfigError(fmt.Sprintf("%s.scenes[%d]", buttonPath, sceneIndex), "scene name is required"))
+				}
+			}
+
+			if (len(button.States) > 1 || len(button.Scenes) > 1) && len(button.CounterID) == 0 {
+				errs = append(errs, newConfigError(joinPath(buttonPath, "counterId"), "counterId is required for cycling through states or scenes"))
+			}
+
+			switch {
+			case len(button.Groups) == 0:
+				errs = append(errs, newConfigError(joinPath(buttonPath, "groups"), "at least one group is required"))
+			case button.Toggle && len(button.Groups) > 1:
+				errs = append(errs, newConfigError(joinPath(buttonPath, "groups"), "toggle applies to a single group, rules of the bridge can't check lights of several ones"))
+			default:
+				name := fmt.Sprintf("Tap %s.%s", tap.ID, button.ID)
+				cycle := len(button.States)
+				if len(button.Scenes) > cycle {
+					cycle = len(button.Scenes)
+				}
+
+				if button.Toggle {
+					name += " off"
+				} else if cycle > 1 {
+					name = fmt.Sprintf("%s #%d", name, cycle)
+				}
+
+				errs = append(errs, validateRuleLimits(buttonPath, name, button.Groups, c.Sensors, cycle > 1)...)
+			}
+		}
+	}
+
+	for index, item := range c.Switches {
+		path := fmt.Sprintf("switches[%d]", index)
+
+		if len(item.ID) == 0 {
+			errs = append(errs, newConfigError(joinPath(path, "id"), "id is required"))
+		}
+
+		events := make(map[int]bool)
+		for buttonIndex, button := range item.Buttons {
+			buttonPath := fmt.Sprintf("%s.buttons[%d]", path, buttonIndex)
+
+			_, knownButton := switchButtonMapping[button.Button]
+			if !knownButton {
+				errs = append(errs, newConfigError(joinPath(buttonPath, "button"), "unknown button `%s`, must be on, up, down, off or between 1 and 4", button.Button))
+			}
+
+			_, knownEvent := switchEventMapping[button.Event]
+			if !knownEvent {
+				errs = append(errs, newConfigError(joinPath(buttonPath, "event"), "unknown event `%s`, must be initial_press, hold, short_release or long_release", button.Event))
+			}
+
+			if knownButton && knownEvent {
+				if events[button.buttonEvent()] {
+					errs = append(errs, newConfigError(buttonPath, "duplicate event `%s` for button `%s`", button.Event, button.Button))
+				}
+				events[button.buttonEvent()] = true
+			}
+
+			switch {
+			case button.BrightnessStep != nil && len(button.States) != 0:
+				errs = append(errs, newConfigError(buttonPath, "states and brightnessStep can't be combined"))
+			case button.BrightnessStep != nil:
+				if *button.BrightnessStep < -100 || *button.BrightnessStep > 100 {
+					errs = append(errs, newConfigError(joinPath(buttonPath, "brightnessStep"), "%d is out of range, must be between -100 and 100", *button.BrightnessStep))
+				}
+			case len(button.States) == 0:
+				errs = append(errs, newConfigError(buttonPath, "states or brightnessStep is required"))
+			default:
+				for stateIndex, state := range button.States {
+					errs = append(errs, validateState(fmt.Sprintf("%s.states[%d]", buttonPath, stateIndex), state, states)...)
+				}
+
+				if len(button.States) > 1 && len(button.CounterID) == 0 {
+					errs = append(errs, newConfigError(joinPath(buttonPath, "counterId"), "counterId is required for cycling through states"))
+				}
+			}
+
+			if len(button.Groups) == 0 {
+				errs = append(errs, newConfigError(joinPath(buttonPath, "groups"), "at least one group is required"))
+			} else {
+				name := fmt.Sprintf("Switch %s %s %s", item.ID, button.Button, button.Event)
+				if len(button.States) > 1 {
+					name = fmt.Sprintf("%s #%d", name, len(button.States))
+				}
+
+				errs = append(errs, validateRuleLimits(buttonPath, name, button.Groups, c.Sensors, len(button.States) > 1)...)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateRuleLimits checks the longest rule generated for a button fits in the limits of the bridge
+func validateRuleLimits(path, name string, groups []string, sensors []configSensor, cycling bool) []error {
+	var errs []error
+
+	if len(name) > maxNameLength {
+		errs = append(errs, newConfigError(path, "rule name `%s` is longer than %d characters", name, maxNameLength))
+	}
+
+	actions := len(groups) + len(overrideActions(sensors, groups))
+	if cycling {
+		actions++
+	}
+
+	if actions > maxRuleActions {
+		errs = append(errs, newConfigError(joinPath(path, "groups"), "%d actions for a rule, bridge allows %d: one per group, per companion sensor of the groups and for the counter", actions, maxRuleActions))
+	}
+
+	return errs
+}
+
+// validateBands checks that each band applies a state or a brightness, within a time interval not overlapping the others
+func validateBands(path string, bands []configSensorBand, states map[string]bridge.State) []error {
+	var errs []error
+	var minutes [24 * 60]int
+
+	for index, band := range bands {
+		bandPath := fmt.Sprintf("%s.bands[%d]", path, index)
+
+		switch {
+		case band.Brightness != nil && len(band.State) != 0:
+			errs = append(errs, newConfigError(bandPath, "state and brightness can't be combined"))
+		case band.Brightness != nil:
+			if *band.Brightness < 1 || *band.Brightness > 100 {
+				errs = append(errs, newConfigError(joinPath(bandPath, "brightness"), "%d is out of range, must be between 1 and 100", *band.Brightness))
+			}
+		default:
+			errs = append(errs, validateState(joinPath(bandPath, "state"), band.State, states)...)
+		}
+
+		if _, err := band.interval(); err != nil {
+			errs = append(errs, newConfigError(bandPath, "%s", err))
+			continue
+		}
+
+		start, _ := parseClock(band.Start)
+		end, _ := parseClock(band.End)
+
+		for minute := int(start / time.Minute); minute != int(end/time.Minute); minute = (minute + 1) % len(minutes) {
+			if other := minutes[minute]; other != 0 {
+				errs = append(errs, newConfigError(bandPath, "overlaps bands[%d] at %s", other-1, formatDuration(time.Duration(minute)*time.Minute)))
+				break
+			}
+
+			minutes[minute] = index + 1
+		}
+	}
+
+	return errs
+}
+
+func stateKeys(states map[string]configState) []string {
+	keys := make([]string, 0, len(states))
+	for key := range states {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func validateState(path, name string, states map[string]bridge.State) []error {
+	if _, ok := states[name]; ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(states))
+	for stateName := range states {
+		names = append(names, stateName)
+	}
+
+	return []error{newConfigError(path, "unknown state `%s`, must be one of %s", name, strings.Join(sortedKeys(names), ", "))}
+}
+
+func (c configHue) validateWithBridge(state bridgeState, username string) []error {
+	var errs []error
+
+	checkGroup := func(path, id string) {
+		if _, ok := state.groups[id]; !ok && id != allLightsGroup {
+			errs = append(errs, newConfigError(path, "unknown group `%s` on bridge", id))
+		}
+	}
+
+	checkSensor := func(path, id string, sensorTypes ...string) {
+		sensor, ok := state.sensors[id]
+		if !ok {
+			errs = append(errs, newConfigError(path, "unknown sensor `%s` on bridge", id))
+			return
+		}
+
+		for _, sensorType := range sensorTypes {
+			if sensor.Type == sensorType {
+				return
+			}
+		}
+
+		errs = append(errs, newConfigError(path, "sensor `%s` is a `%s`, must be a %s", id, sensor.Type, strings.Join(sensorTypes, " or ")))
+	}
+
+	unmanagedSchedules := make(map[string]bool)
+	for _, schedule := range state.schedules {
+		if schedule.Description != managedTag && !isLegacySchedule(state, schedule, username) {
+			unmanagedSchedules[schedule.Name] = true
+		}
+	}
+
+	for index, schedule := range c.Schedules {
+		if unmanagedSchedules[schedule.Name] {
+			errs = append(errs, newConfigError(fmt.Sprintf("schedules[%d].name", index), "schedule `%s` already exists on bridge and isn't managed by the configuration file", schedule.Name))
+		}
+
+		if _, ok := state.groups[schedule.Group]; !ok {
+			errs = append(errs, newConfigError(fmt.Sprintf("schedules[%d].group", index), "unknown group `%s` on bridge", schedule.Group))
+		}
+	}
+
+	for index, sensor := range c.Sensors {
+		path := fmt.Sprintf("sensors[%d]", index)
+
+		checkSensor(joinPath(path, "id"), sensor.ID, presenceSensorType)
+		checkSensor(joinPath(path, "lightSensorId"), sensor.LightSensorID, lightLevelSensorType)
+
+		if len(sensor.CompanionID) != 0 {
+			checkSensor(joinPath(path, "companionId"), sensor.CompanionID, statusSensorType)
+		}
+
+		for groupIndex, group := range sensor.Groups {
+			checkGroup(fmt.Sprintf("%s.groups[%d]", path, groupIndex), group)
+		}
+	}
+
+	for index, tap := range c.Taps {
+		path := fmt.Sprintf("taps[%d]", index)
+
+		checkSensor(joinPath(path, "id"), tap.ID, tapSensorType)
+
+		for buttonIndex, button := range tap.Buttons {
+			buttonPath := fmt.Sprintf("%s.buttons[%d]", path, buttonIndex)
+
+			if len(button.CounterID) != 0 {
+				checkSensor(joinPath(buttonPath, "counterId"), button.CounterID, statusSensorType)
+			}
+
+			for groupIndex, group := range button.Groups {
+				checkGroup(fmt.Sprintf("%s.groups[%d]", buttonPath, groupIndex), group)
+
+				for sceneIndex, scene := range button.Scenes {
+					if _, ok := findScene(state, group, scene); !ok {
+						errs = append(errs, newConfigError(fmt.Sprintf("%s.scenes[%d]", buttonPath, sceneIndex), "unknown scene `%s` for group `%s` on bridge", scene, group))
+					}
+				}
+			}
+		}
+	}
+
+	for index, item := range c.Switches {
+		path := fmt.Sprintf("switches[%d]", index)
+
+		checkSensor(joinPath(path, "id"), item.ID, switchSensorType)
+		smartButton := isSmartButton(state.sensors[item.ID])
+
+		for buttonIndex, button := range item.Buttons {
+			buttonPath := fmt.Sprintf("%s.buttons[%d]", path, buttonIndex)
+
+			if smartButton && switchButtonMapping[button.Button] > 1 {
+				errs = append(errs, newConfigError(joinPath(buttonPath, "button"), "smart button `%s` has a single button, must be on or 1", item.ID))
+			}
+
+			if len(button.CounterID) != 0 {
+				checkSensor(joinPath(buttonPath, "counterId"), button.CounterID, statusSensorType)
+			}
+
+			for groupIndex, group := range button.Groups {
+				checkGroup(fmt.Sprintf("%s.groups[%d]", buttonPath, groupIndex), group)
+			}
+		}
+	}
+
+	return errs
+}
+
+// Validate checks configuration against current bridge content
+func (a *app) Validate(ctx context.Context) error {
+	a.mutex.RLock()
+	config := a.config
+	a.mutex.RUnlock()
+
+	if config == nil {
+		return nil
+	}
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	state, err := a.fetchBridgeState(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch bridge state: %s", err)
+	}
+
+	if errs := config.validateWithBridge(state, a.bridgeUsername); len(errs) > 0 {
+		return configErrors(errs)
+	}
+
+	return nil
+}
@@ -1,27 +1,73 @@
 package hue
 
 type configHue struct {
-	Schedules []ScheduleConfig
-	Sensors   []configSensor
-	Taps      []configTap
+	Location  *configLocation        `json:"location"`
+	States    map[string]configState `json:"states"`
+	Schedules []ScheduleConfig       `json:"schedules"`
+	Sensors   []configSensor         `json:"sensors"`
+	Taps      []configTap            `json:"taps"`
+	Switches  []configSwitch         `json:"switches"`
+}
+
+type configLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type configState struct {
+	On             *bool     `json:"on"`
+	Bri            *int      `json:"bri"`
+	Ct             *int      `json:"ct"`
+	Hue            *int      `json:"hue"`
+	Sat            *int      `json:"sat"`
+	TransitionTime *int      `json:"transitiontime"`
+	Effect         string    `json:"effect"`
+	XY             []float64 `json:"xy"`
 }
 
 type configSensor struct {
-	ID            string
-	LightSensorID string
-	CompanionID   string
-	OffDelay      string
-	Groups        []string
+	LightLevel    *int               `json:"lightlevel"`
+	ID            string             `json:"id"`
+	LightSensorID string             `json:"lightSensorId"`
+	CompanionID   string             `json:"companionId"`
+	OffDelay      string             `json:"offDelay"`
+	OffState      string             `json:"offState"`
+	Groups        []string           `json:"groups"`
+	Bands         []configSensorBand `json:"bands"`
+}
+
+type configSensorBand struct {
+	Brightness *int   `json:"brightness"`
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	State      string `json:"state"`
 }
 
 type configTap struct {
-	ID      string
-	Buttons []configTapButton
+	ID      string            `json:"id"`
+	Buttons []configTapButton `json:"buttons"`
 }
 
 type configTapButton struct {
-	ID     string
-	State  string
-	Groups []string
-	Rule   Rule
+	ID        string   `json:"id"`
+	State     string   `json:"state"`
+	States    []string `json:"states"`
+	Scenes    []string `json:"scenes"`
+	Toggle    bool     `json:"toggle"`
+	CounterID string   `json:"counterId"`
+	Groups    []string `json:"groups"`
+}
+
+type configSwitch struct {
+	ID      string               `json:"id"`
+	Buttons []configSwitchButton `json:"buttons"`
+}
+
+type configSwitchButton struct {
+	BrightnessStep *int     `json:"brightnessStep"`
+	Button         string   `json:"button"`
+	Event          string   `json:"event"`
+	CounterID      string   `json:"counterId"`
+	States         []string `json:"states"`
+	Groups         []string `json:"groups"`
 }
@@ -0,0 +1,328 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func errorsString(errs []error) []string {
+	output := make([]string, len(errs))
+	for index, err := range errs {
+		output[index] = err.Error()
+	}
+
+	return output
+}
+
+func TestCheckUnknownFields(t *testing.T) {
+	type args struct {
+		content map[string]interface{}
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      []string
+	}{
+		{
+			"valid",
+			args{
+				content: map[string]interface{}{
+					"taps": []interface{}{
+						map[string]interface{}{
+							"id":      "2",
+							"buttons": []interface{}{map[string]interface{}{"id": "1", "state": "on"}},
+						},
+					},
+				},
+			},
+			[]string{},
+		},
+		{
+			"case insensitive",
+			args{
+				content: map[string]interface{}{
+					"sensors": []interface{}{map[string]interface{}{"LightSensorID": "7"}},
+				},
+			},
+			[]string{},
+		},
+		{
+			"unknown fields",
+			args{
+				content: map[string]interface{}{
+					"scenes": []interface{}{},
+					"taps": []interface{}{
+						map[string]interface{}{
+							"buttons": []interface{}{
+								map[string]interface{}{"id": "1"},
+								map[string]interface{}{"id": "2", "colour": "red"},
+							},
+						},
+					},
+				},
+			},
+			[]string{"scenes: unknown field", "taps[0].buttons[1].colour: unknown field"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := errorsString(checkUnknownFields(tc.args.content, reflect.TypeOf(configHue{}), "")); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("checkUnknownFields() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type args struct {
+		config configHue
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      []string
+	}{
+		{
+			"valid",
+			args{
+				config: configHue{
+					Schedules: []ScheduleConfig{{Name: "Wake Up", Localtime: "W124/T07:55:00", Group: "2", State: "long_on"}},
+					Sensors:   []configSensor{{ID: "6", LightSensorID: "7", OffDelay: "PT00:01:00", Groups: []string{"4"}}},
+					Taps:      []configTap{{ID: "2", Buttons: []configTapButton{{ID: "1", State: "on", Groups: []string{"2"}}}}},
+				},
+			},
+			[]string{},
+		},
+		{
+			"schedule",
+			args{
+				config: configHue{
+					Schedules: []ScheduleConfig{{Name: "Wake Up", Localtime: "W124 07:55", Group: "2", State: "sunrise"}},
+				},
+			},
+			[]string{
+				"schedules[0].localtime: malformed time pattern `W124 07:55`, e.g. `W124/T07:55:00` or `sunset - 30m`",
+				"schedules[0].state: unknown state `sunrise`, must be one of dimmed, half, long_off, long_on, off, on",
+			},
+		},
+		{
+			"solar schedule",
+			args{
+				config: configHue{
+					Location: &configLocation{Latitude: 98, Longitude: 2.35},
+					Schedules: []ScheduleConfig{
+						{Name: "Evening", Localtime: "sunset - 30m", Group: "2", State: "on"},
+						{Name: "Morning", Localtime: "sunrise + 1 hour", Group: "2", State: "off"},
+					},
+				},
+			},
+			[]string{
+				"location.latitude: latitude must be between -90 and 90",
+				"schedules[1].localtime: malformed solar time `sunrise + 1 hour`, e.g. `sunset - 30m`",
+			},
+		},
+		{
+			"solar schedule without location",
+			args{
+				config: configHue{
+					Schedules: []ScheduleConfig{{Name: "Evening", Localtime: "sunset", Group: "2", State: "on"}},
+				},
+			},
+			[]string{"schedules[0].localtime: location is required for solar time `sunset`"},
+		},
+		{
+			"custom states",
+			args{
+				config: configHue{
+					States: map[string]configState{
+						"reading": {Bri: intPointer(178), Ct: intPointer(366)},
+						"movie":   {Bri: intPointer(0), Hue: intPointer(46920), XY: []float64{0.1, 1.2}, Effect: "blink"},
+						"on":      {Bri: intPointer(100)},
+					},
+					Schedules: []ScheduleConfig{{Name: "Evening", Localtime: "W127/T20:00:00", Group: "2", State: "reading"}},
+				},
+			},
+			[]string{
+				"states.movie.bri: 0 is out of range, must be between 1 and 254",
+				"states.movie.xy[1]: 1.2 is out of range, must be between 0 and 1",
+				"states.movie.effect: unknown effect `blink`, must be none or colorloop",
+				"states.movie: only one of ct, hue/sat or xy can be set",
+				"states.on: `on` is a built-in state and can't be redefined",
+			},
+		},
+		{
+			"sensor",
+			args{
+				config: configHue{
+					Sensors: []configSensor{{ID: "6", LightSensorID: "7", OffDelay: "1m", Groups: []string{"4"}}},
+				},
+			},
+			[]string{"sensors[0].offDelay: malformed duration `1m`, e.g. `PT00:01:00`"},
+		},
+		{
+			"sensor bands",
+			args{
+				config: configHue{
+					Sensors: []configSensor{{ID: "6", LightSensorID: "7", OffDelay: "PT00:01:00", Groups: []string{"4"}, LightLevel: intPointer(70000), Bands: []configSensorBand{
+						{Start: "07:00", End: "22:00", Brightness: intPointer(100)},
+						{Start: "22:00", End: "07:00", State: "dimmed"},
+						{Start: "06:00", End: "08:00", State: "bright"},
+						{Start: "9h", End: "10:00", State: "on", Brightness: intPointer(0)},
+					}}},
+				},
+			},
+			[]string{
+				"sensors[0].lightlevel: 70000 is out of range, must be between 0 and 65535",
+				"sensors[0].bands[2].state: unknown state `bright`, must be one of dimmed, half, long_off, long_on, off, on",
+				"sensors[0].bands[2]: overlaps bands[1] at 06:00:00",
+				"sensors[0].bands[3]: state and brightness can't be combined",
+				"sensors[0].bands[3]: malformed start `9h`, e.g. `07:00`",
+			},
+		},
+		{
+			"sensor rules",
+			args{
+				config: configHue{
+					Sensors: []configSensor{{ID: "6", LightSensorID: "7", CompanionID: "9", OffDelay: "PT00:01:00", OffState: "on", Groups: []string{"1", "2", "3", "4", "5", "6", "7", "123456789"}}},
+				},
+			},
+			[]string{
+				"sensors[0].groups: 9 actions for a rule, bridge allows 8: one per group, per companion sensor of the groups and for the counter",
+				"sensors[0]: rule name `MotionSensor 6 - on` is generated twice, offState can't be named like a band or a rule of another sensor",
+				"sensors[0]: rule name `MotionSensor 6 - manual 123456789` is longer than 32 characters",
+			},
+		},
+		{
+			"tap",
+			args{
+				config: configHue{
+					Taps: []configTap{{ID: "2", Buttons: []configTapButton{
+						{ID: "1", State: "on", Groups: []string{"2"}},
+						{ID: "5", State: "off", Groups: []string{"2"}},
+						{ID: "1", State: "off"},
+					}}},
+				},
+			},
+			[]string{
+				"taps[0].buttons[1].id: unknown button `5`, must be between 1 and 4",
+				"taps[0].buttons[2].id: duplicate button `1`",
+				"taps[0].buttons[2].groups: at least one group is required",
+			},
+		},
+		{
+			"tap actions",
+			args{
+				config: configHue{
+					Taps: []configTap{{ID: "2", Buttons: []configTapButton{
+						{ID: "1", Toggle: true, State: "half", Groups: []string{"2"}},
+						{ID: "2", Toggle: true, Scenes: []string{"Relax"}, Groups: []string{"2"}},
+						{ID: "3", States: []string{"on", "off"}, Groups: []string{"2"}},
+						{ID: "4", Groups: []string{"2"}},
+					}}},
+				},
+			},
+			[]string{
+				"taps[0].buttons[1]: toggle can only be combined with state",
+				"taps[0].buttons[2].counterId: counterId is required for cycling through states or scenes",
+				"taps[0].buttons[3]: one of state, states, scenes or toggle is required",
+			},
+		},
+		{
+			"tap rule limits",
+			args{
+				config: configHue{
+					Taps: []configTap{{ID: "123456789012345678901234", Buttons: []configTapButton{
+						{ID: "1", Toggle: true, Groups: []string{"1", "2"}},
+						{ID: "2", Scenes: []string{"Relax", "Concentrate"}, CounterID: "9", Groups: []string{"1"}},
+						{ID: "3", State: "on", Groups: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}},
+					}}},
+				},
+			},
+			[]string{
+				"taps[0].buttons[0].groups: toggle applies to a single group, rules of the bridge can't check lights of several ones",
+				"taps[0].buttons[1]: rule name `Tap 123456789012345678901234.2 #2` is longer than 32 characters",
+				"taps[0].buttons[2].groups: 9 actions for a rule, bridge allows 8: one per group, per companion sensor of the groups and for the counter",
+			},
+		},
+		{
+			"switch",
+			args{
+				config: configHue{
+					Switches: []configSwitch{{ID: "10", Buttons: []configSwitchButton{
+						{Button: "on", Event: "short_release", States: []string{"on", "half"}, Groups: []string{"2"}},
+						{Button: "up", Event: "hold", BrightnessStep: intPointer(150), Groups: []string{"2"}},
+						{Button: "5", Event: "click", Groups: []string{"2"}},
+						{Button: "1", Event: "short_release", States: []string{"off"}, Groups: []string{"2"}},
+					}}},
+				},
+			},
+			[]string{
+				"switches[0].buttons[0].counterId: counterId is required for cycling through states",
+				"switches[0].buttons[1].brightnessStep: 150 is out of range, must be between -100 and 100",
+				"switches[0].buttons[2].button: unknown button `5`, must be on, up, down, off or between 1 and 4",
+				"switches[0].buttons[2].event: unknown event `click`, must be initial_press, hold, short_release or long_release",
+				"switches[0].buttons[2]: states or brightnessStep is required",
+				"switches[0].buttons[3]: duplicate event `short_release` for button `1`",
+			},
+		},
+		{
+			"switch rule limits",
+			args{
+				config: configHue{
+					Sensors: []configSensor{{ID: "5", LightSensorID: "6", CompanionID: "9", OffDelay: "PT00:05:00", Groups: []string{"1"}}},
+					Switches: []configSwitch{{ID: "1234", Buttons: []configSwitchButton{
+						{Button: "down", Event: "initial_press", States: []string{"on", "off"}, CounterID: "12", Groups: []string{"2"}},
+						{Button: "on", Event: "hold", States: []string{"on"}, Groups: []string{"1", "2", "3", "4", "5", "6", "7", "8"}},
+					}}},
+				},
+			},
+			[]string{
+				"switches[0].buttons[0]: rule name `Switch 1234 down initial_press #2` is longer than 32 characters",
+				"switches[0].buttons[1].groups: 9 actions for a rule, bridge allows 8: one per group, per companion sensor of the groups and for the counter",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := errorsString(tc.args.config.validate()); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("validate() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateWithBridge(t *testing.T) {
+	state := bridgeState{
+		groups:    map[string]Group{"2": {Group: bridge.Group{Name: "Bedroom"}}},
+		schedules: map[string]bridge.Schedule{"1": {ID: "1", APISchedule: bridge.APISchedule{Name: "Wake Up"}}},
+		sensors: map[string]bridge.Sensor{
+			"6": {Type: presenceSensorType},
+			"7": {Type: lightLevelSensorType},
+		},
+	}
+
+	config := configHue{
+		Schedules: []ScheduleConfig{{Name: "Wake Up", Group: "3"}},
+		Sensors:   []configSensor{{ID: "6", LightSensorID: "6", Groups: []string{"2", "0"}}},
+		Taps:      []configTap{{ID: "8", Buttons: []configTapButton{{ID: "1", Scenes: []string{"Relax"}, Groups: []string{"4"}}}}},
+	}
+
+	want := []string{
+		"schedules[0].name: schedule `Wake Up` already exists on bridge and isn't managed by the configuration file",
+		"schedules[0].group: unknown group `3` on bridge",
+		"sensors[0].lightSensorId: sensor `6` is a `ZLLPresence`, must be a ZLLLightLevel",
+		"taps[0].id: unknown sensor `8` on bridge",
+		"taps[0].buttons[0].groups[0]: unknown group `4` on bridge",
+		"taps[0].buttons[0].scenes[0]: unknown scene `Relax` for group `4` on bridge",
+	}
+
+	if got := errorsString(config.validateWithBridge(state, "secret")); !reflect.DeepEqual(got, want) {
+		t.Errorf("validateWithBridge() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package hue
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	eventRetryInterval = time.Second * 5
+)
+
+var (
+	clipResourceTypes = []string{"light", "motion", "temperature", "device_power"}
+)
+
+func (a *app) streamEvents(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	for {
+		if err := a.syncResources(ctx); err != nil {
+			logger.Error("unable to sync resources from bridge: %s", err)
+		} else if err := a.clip.Events(ctx, a.applyEvent); err != nil && ctx.Err() == nil {
+			logger.Error("event stream interrupted: %s", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-time.After(eventRetryInterval):
+		}
+	}
+}
+
+func (a *app) syncResources(ctx context.Context) error {
+	for _, resourceType := range clipResourceTypes {
+		resources, err := a.clip.ListResources(ctx, resourceType)
+		if err != nil {
+			return err
+		}
+
+		a.applyResources(resources)
+	}
+
+	return nil
+}
+
+func (a *app) applyEvent(event bridge.Event) {
+	if event.Type != "update" {
+		return
+	}
+
+	a.applyResources(event.Data)
+}
+
+func (a *app) applyResources(resources []bridge.Resource) {
+	sensorsChanged := false
+
+	a.mutex.Lock()
+
+	for _, resource := range resources {
+		if a.applyResource(resource) {
+			sensorsChanged = true
+		}
+	}
+
+	a.mutex.Unlock()
+
+	a.publishChanges()
+
+	if sensorsChanged {
+		go a.updatePrometheusSensors()
+	}
+}
+
+func (a *app) applyResource(resource bridge.Resource) bool {
+	kind, id := resource.V1()
+
+	switch kind {
+	case "lights":
+		light, ok := a.lights[id]
+		if !ok {
+			return false
+		}
+
+		if resource.On != nil {
+			light.State.On = resource.On.On
+		}
+
+		if resource.Dimming != nil {
+			light.State.Bri = int(math.Round(resource.Dimming.Brightness * 254 / 100))
+		}
+
+		a.lights[id] = light
+		a.refreshGroupsState()
+
+		return false
+
+	case "sensors":
+		name, ok := a.sensorNames[id]
+		if !ok {
+			return false
+		}
+
+		sensor, ok := a.sensors[name]
+		if !ok {
+			return false
+		}
+
+		if resource.Motion != nil {
+			sensor.State.Presence = resource.Motion.Motion
+		}
+
+		if resource.Temperature != nil && resource.Temperature.TemperatureValid {
+			sensor.State.Temperature = resource.Temperature.Temperature
+		}
+
+		if resource.PowerState != nil {
+			sensor.Config.Battery = resource.PowerState.BatteryLevel
+		}
+
+		a.sensors[name] = sensor
+
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+package hue
+
+import (
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func TestApplyResources(t *testing.T) {
+	type args struct {
+		resources []bridge.Resource
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		wantAnyOn bool
+		wantAllOn bool
+		wantMove  bool
+		wantTemp  float32
+	}{
+		{
+			"light on",
+			args{
+				resources: []bridge.Resource{{IDV1: "/lights/1", Type: "light", On: &bridge.ResourceOn{On: true}}},
+			},
+			true,
+			false,
+			false,
+			20,
+		},
+		{
+			"all lights on",
+			args{
+				resources: []bridge.Resource{
+					{IDV1: "/lights/1", Type: "light", On: &bridge.ResourceOn{On: true}},
+					{IDV1: "/lights/2", Type: "light", On: &bridge.ResourceOn{On: true}},
+				},
+			},
+			true,
+			true,
+			false,
+			20,
+		},
+		{
+			"motion and temperature",
+			args{
+				resources: []bridge.Resource{
+					{IDV1: "/sensors/5", Type: "motion", Motion: &bridge.ResourceMotion{Motion: true, MotionValid: true}},
+					{IDV1: "/sensors/7", Type: "temperature", Temperature: &bridge.ResourceTemperature{Temperature: 21.5, TemperatureValid: true}},
+				},
+			},
+			false,
+			false,
+			true,
+			21.5,
+		},
+		{
+			"unknown resources",
+			args{
+				resources: []bridge.Resource{
+					{IDV1: "/lights/42", Type: "light", On: &bridge.ResourceOn{On: true}},
+					{Type: "zigbee_connectivity"},
+				},
+			},
+			false,
+			false,
+			false,
+			20,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			instance := &app{
+				groups: map[string]Group{
+					"1": {Group: bridge.Group{Name: "Living room", Lights: []string{"1", "2"}}},
+				},
+				lights: map[string]bridge.Light{
+					"1": {Name: "Lamp"},
+					"2": {Name: "Spot"},
+				},
+				sensors: map[string]bridge.Sensor{
+					"Hallway": {ID: "5", Name: "Hallway", State: bridge.SensorState{Temperature: 20}},
+				},
+				sensorNames: map[string]string{"5": "Hallway", "6": "Hallway", "7": "Hallway"},
+			}
+
+			instance.applyResources(tc.args.resources)
+
+			group := instance.groups["1"]
+			sensor := instance.sensors["Hallway"]
+
+			if group.State.AnyOn != tc.wantAnyOn || group.State.AllOn != tc.wantAllOn || sensor.State.Presence != tc.wantMove || sensor.State.Temperature != tc.wantTemp {
+				t.Errorf("applyResources() = (%t, %t, %t, %g), want (%t, %t, %t, %g)", group.State.AnyOn, group.State.AllOn, sensor.State.Presence, sensor.State.Temperature, tc.wantAnyOn, tc.wantAllOn, tc.wantMove, tc.wantTemp)
+			}
+		})
+	}
+}
@@ -2,39 +2,52 @@ package hue
 
 import (
 	"context"
-	"fmt"
-	"strings"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
-func (a *app) listGroups(ctx context.Context) (map[string]Group, error) {
-	var groups map[string]Group
-	err := get(ctx, fmt.Sprintf("%s/groups", a.bridgeURL), &groups)
+func (a *app) listGroups(ctx context.Context) (map[string]Group, map[string]bridge.Light, error) {
+	groups, err := a.client.ListGroups(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lights, err := a.client.ListLights(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	output := make(map[string]Group, len(groups))
 
 	for key, value := range groups {
-		value.Tap = false
+		group := Group{
+			Group: value,
+		}
 
 		for _, lightID := range value.Lights {
-			light, err := a.getLight(ctx, lightID)
-			if err != nil {
-				return nil, err
-			}
-
-			if strings.HasPrefix(light.Type, "On/Off") {
-				value.Tap = true
+			if !lights[lightID].IsDimmable() {
+				group.Tap = true
 			}
 		}
 
-		output[key] = value
+		output[key] = group
 	}
 
-	return output, nil
+	return output, lights, nil
 }
 
-func (a *app) updateGroupState(ctx context.Context, groupID string, state interface{}) error {
-	return update(ctx, fmt.Sprintf("%s/groups/%s/action", a.bridgeURL, groupID), state)
+func (a *app) refreshGroupsState() {
+	for id, group := range a.groups {
+		group.State.AnyOn = false
+		group.State.AllOn = len(group.Lights) != 0
+
+		for _, lightID := range group.Lights {
+			on := a.lights[lightID].State.On
+
+			group.State.AnyOn = group.State.AnyOn || on
+			group.State.AllOn = group.State.AllOn && on
+		}
+
+		a.groups[id] = group
+	}
 }
@@ -1,6 +1,8 @@
 package hue
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,13 +11,19 @@ import (
 	"github.com/ViBiOh/httputils/v4/pkg/httperror"
 	"github.com/ViBiOh/httputils/v4/pkg/model"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
 const (
 	apiPath       = "/api"
 	groupsPath    = "/groups"
+	lightsPath    = "/lights"
+	scenesPath    = "/scenes"
 	schedulesPath = "/schedules"
 	sensorsPath   = "/sensors"
+	vacationPath  = "/vacation"
+	reloadPath    = "/reload"
+	eventsPath    = "/events"
 
 	updateSuccessMessage = "%s is now %s"
 )
@@ -23,11 +31,36 @@ const (
 // Handler for request. Should be use with net/http
 func (a *app) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.isConnected() {
+			bridgeUnavailable(w)
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, v1Path+"/") || r.URL.Path == v1Path {
+			a.handleV1(w, r)
+			return
+		}
+
+		if r.URL.Path == eventsPath {
+			a.handleEvents(w, r)
+			return
+		}
+
 		if strings.HasPrefix(r.URL.Path, groupsPath) {
 			a.handleGroup(w, r)
 			return
 		}
 
+		if strings.HasPrefix(r.URL.Path, lightsPath) {
+			a.handleLight(w, r)
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, scenesPath) {
+			a.handleScene(w, r)
+			return
+		}
+
 		if strings.HasPrefix(r.URL.Path, schedulesPath) {
 			a.handleSchedule(w, r)
 			return
@@ -38,79 +71,415 @@ func (a *app) Handler() http.Handler {
 			return
 		}
 
+		if r.URL.Path == vacationPath {
+			a.handleVacation(w, r)
+			return
+		}
+
+		if r.URL.Path == reloadPath {
+			a.handleReload(w, r)
+			return
+		}
+
 		httperror.NotFound(w)
 	})
 }
 
 func (a *app) handleGroup(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, scenesPath) {
+		a.handleGroupScene(w, r)
+		return
+	}
+
 	if r.FormValue("method") != http.MethodPatch {
 		a.rendererApp.Error(w, model.WrapNotFound(fmt.Errorf("invalid method for updating group")))
 		return
 	}
 
-	groupID := strings.Trim(strings.TrimPrefix(r.URL.Path, groupsPath), "/")
-	stateName := r.FormValue("state")
+	adjust, err := parseAdjustmentForm(r)
+	if err != nil {
+		a.rendererApp.Error(w, err)
+		return
+	}
 
-	group, ok := a.groups[groupID]
-	if !ok {
-		a.rendererApp.Error(w, model.WrapNotFound(fmt.Errorf("unknown group '%s'", groupID)))
+	group, err := a.adjustGroup(r.Context(), strings.Trim(strings.TrimPrefix(r.URL.Path, groupsPath), "/"), adjust)
+	if err != nil {
+		a.rendererApp.Error(w, err)
 		return
 	}
 
-	state, ok := States[stateName]
-	if !ok {
-		a.rendererApp.Error(w, model.WrapNotFound(fmt.Errorf("unknown state '%s'", stateName)))
+	description := adjust.String()
+	if len(adjust.Scene) != 0 {
+		a.mutex.RLock()
+		description = fmt.Sprintf("on scene %s", a.scenes[adjust.Scene].Name)
+		a.mutex.RUnlock()
+	}
+
+	a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf(updateSuccessMessage, group.Name, description)))
+}
+
+func (a *app) handleGroupScene(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("method") != http.MethodPost {
+		a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for creating scene")))
 		return
 	}
 
-	if err := a.updateGroupState(r.Context(), groupID, state); err != nil {
+	groupID := strings.Trim(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, groupsPath), scenesPath), "/")
+
+	scene, err := a.createScene(r.Context(), groupID, r.FormValue("name"))
+	if err != nil {
 		a.rendererApp.Error(w, err)
 		return
 	}
 
+	a.rendererApp.Redirect(w, r, scenesPath+"/"+scene.ID, renderer.NewSuccessMessage(fmt.Sprintf("%s is now created from current lights", scene.Name)))
+}
+
+func (a *app) handleScene(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, scenesPath), "/"), "/")
+	sceneID := parts[0]
+
+	if len(parts) == 3 && parts[1] == "lightstates" {
+		if r.FormValue("method") != http.MethodPut {
+			a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for updating scene light")))
+			return
+		}
+
+		adjust, err := parseAdjustmentForm(r)
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		scene, err := a.updateSceneLightState(r.Context(), sceneID, parts[2], adjust)
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, scenesPath+"/"+sceneID, renderer.NewSuccessMessage(fmt.Sprintf("Light of %s is now %s", scene.Name, adjust)))
+		return
+	}
+
+	if len(parts) != 1 {
+		a.rendererApp.Error(w, model.WrapNotFound(fmt.Errorf("unknown path `%s`", r.URL.Path)))
+		return
+	}
+
+	switch r.FormValue("method") {
+	case http.MethodPatch:
+		var scene bridge.Scene
+		var err error
+
+		if r.FormValue("capture") == "true" {
+			scene, err = a.captureScene(r.Context(), sceneID)
+		} else {
+			scene, err = a.renameScene(r.Context(), sceneID, r.FormValue("name"))
+		}
+
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, scenesPath+"/"+sceneID, renderer.NewSuccessMessage(fmt.Sprintf("%s is now updated", scene.Name)))
+
+	case http.MethodDelete:
+		scene, err := a.deleteScene(r.Context(), sceneID)
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf("%s is now deleted", scene.Name)))
+
+	default:
+		a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for updating scene")))
+	}
+}
+
+func parseAdjustmentForm(r *http.Request) (adjustment, error) {
+	adjust := adjustment{
+		State: r.FormValue("state"),
+		Scene: r.FormValue("scene"),
+		Color: r.FormValue("color"),
+	}
+
+	if value := r.FormValue("on"); len(value) != 0 {
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return adjust, model.WrapInvalid(fmt.Errorf("unable to parse boolean with value `%s`: %s", value, err))
+		}
+
+		adjust.On = &on
+	}
+
+	var err error
+
+	if adjust.Brightness, err = formInt(r, "brightness"); err != nil {
+		return adjust, err
+	}
+
+	if adjust.BrightnessStep, err = formInt(r, "brightnessStep"); err != nil {
+		return adjust, err
+	}
+
+	if adjust.Kelvin, err = formInt(r, "kelvin"); err != nil {
+		return adjust, err
+	}
+
+	if adjust.Mirek, err = formInt(r, "mirek"); err != nil {
+		return adjust, err
+	}
+
+	if adjust.MirekStep, err = formInt(r, "mirekStep"); err != nil {
+		return adjust, err
+	}
+
+	if adjust.TransitionTime, err = formInt(r, "transitiontime"); err != nil {
+		return adjust, err
+	}
+
+	return adjust, nil
+}
+
+func (a *app) updateGroupState(ctx context.Context, groupID, stateName string) (Group, error) {
+	a.mutex.RLock()
+	_, ok := a.groups[groupID]
+	state, stateOk := a.states[stateName]
+	a.mutex.RUnlock()
+
+	if !ok {
+		return Group{}, model.WrapNotFound(fmt.Errorf("unknown group '%s'", groupID))
+	}
+
+	if !stateOk {
+		return Group{}, model.WrapInvalid(fmt.Errorf("unknown state '%s'", stateName))
+	}
+
+	if err := a.client.UpdateGroupAction(ctx, groupID, state); err != nil {
+		return Group{}, wrapBridgeError(err)
+	}
+
+	a.overrideMotion(ctx, groupID)
+
 	if err := a.syncGroups(); err != nil {
+		return Group{}, wrapBridgeError(err)
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.groups[groupID], nil
+}
+
+func (a *app) handleLight(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("method") != http.MethodPatch {
+		a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for updating light")))
+		return
+	}
+
+	var state configState
+	var err error
+
+	if value := r.FormValue("on"); len(value) != 0 {
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			a.rendererApp.Error(w, model.WrapInvalid(fmt.Errorf("unable to parse boolean with value `%s`: %s", value, err)))
+			return
+		}
+
+		state.On = &on
+	}
+
+	if state.Bri, err = formInt(r, "bri"); err != nil {
+		a.rendererApp.Error(w, err)
+		return
+	}
+
+	if state.Ct, err = formInt(r, "ct"); err != nil {
+		a.rendererApp.Error(w, err)
+		return
+	}
+
+	if state.Hue, err = formInt(r, "hue"); err != nil {
+		a.rendererApp.Error(w, err)
+		return
+	}
+
+	if state.Sat, err = formInt(r, "sat"); err != nil {
 		a.rendererApp.Error(w, err)
 		return
 	}
 
-	a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf(updateSuccessMessage, group.Name, stateName)))
+	light, err := a.updateLightState(r.Context(), strings.Trim(strings.TrimPrefix(r.URL.Path, lightsPath), "/"), state)
+	if err != nil {
+		a.rendererApp.Error(w, err)
+		return
+	}
+
+	stateName := "off"
+	if light.State.On {
+		stateName = fmt.Sprintf("on at %d%%", brightnessPercent(light.State.Bri))
+	}
+
+	a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf(updateSuccessMessage, light.Name, stateName)))
+}
+
+func formInt(r *http.Request, name string) (*int, error) {
+	value := r.FormValue(name)
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, model.WrapInvalid(fmt.Errorf("unable to parse %s with value `%s`: %s", name, value, err))
+	}
+
+	return &number, nil
 }
 
 func (a *app) handleSchedule(w http.ResponseWriter, r *http.Request) {
-	if r.FormValue("method") != http.MethodPatch {
-		a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for updating schedule")))
+	scheduleID := strings.Trim(strings.TrimPrefix(r.URL.Path, schedulesPath), "/")
+
+	if len(scheduleID) == 0 {
+		if r.FormValue("method") != http.MethodPost {
+			a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for creating schedule")))
+			return
+		}
+
+		schedule, err := a.createSchedule(r.Context(), parseScheduleForm(r))
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf("%s is now created", schedule.Name)))
 		return
 	}
 
-	status := r.FormValue("status")
+	switch r.FormValue("method") {
+	case http.MethodPatch:
+		status := r.FormValue("status")
+
+		schedule, err := a.updateScheduleStatus(r.Context(), scheduleID, status)
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf(updateSuccessMessage, schedule.Name, status)))
+
+	case http.MethodPut:
+		schedule, err := a.updateSchedule(r.Context(), scheduleID, parseScheduleForm(r))
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf("%s is now updated", schedule.Name)))
+
+	case http.MethodDelete:
+		schedule, err := a.deleteSchedule(r.Context(), scheduleID)
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
 
-	schedule := Schedule{
-		ID: strings.Trim(strings.TrimPrefix(r.URL.Path, schedulesPath), "/"),
-		APISchedule: APISchedule{
+		a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf("%s is now deleted", schedule.Name)))
+
+	default:
+		a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for updating schedule")))
+	}
+}
+
+// parseScheduleForm reads a schedule from a form, its action being either `state:<name>` or `scene:<id>`
+func parseScheduleForm(r *http.Request) scheduleRequest {
+	request := scheduleRequest{
+		Name:   r.FormValue("name"),
+		Group:  r.FormValue("group"),
+		Kind:   bridge.PatternKind(r.FormValue("kind")),
+		Days:   r.Form["days"],
+		Time:   r.FormValue("time"),
+		Random: r.FormValue("random"),
+	}
+
+	action := r.FormValue("action")
+	switch {
+	case strings.HasPrefix(action, "state:"):
+		request.State = strings.TrimPrefix(action, "state:")
+	case strings.HasPrefix(action, "scene:"):
+		request.Scene = strings.TrimPrefix(action, "scene:")
+	}
+
+	return request
+}
+
+func (a *app) updateScheduleStatus(ctx context.Context, scheduleID, status string) (bridge.Schedule, error) {
+	if status != "enabled" && status != "disabled" {
+		return bridge.Schedule{}, model.WrapInvalid(fmt.Errorf("unknown status '%s', must be enabled or disabled", status))
+	}
+
+	schedule := bridge.Schedule{
+		ID: scheduleID,
+		APISchedule: bridge.APISchedule{
 			Status: status,
 		},
 	}
 
-	if err := a.updateSchedule(r.Context(), schedule); err != nil {
-		a.rendererApp.Error(w, err)
-		return
+	if err := a.client.UpdateSchedule(ctx, schedule); err != nil {
+		return bridge.Schedule{}, wrapBridgeError(err)
 	}
 
 	if err := a.syncSchedules(); err != nil {
-		a.rendererApp.Error(w, err)
-		return
+		return bridge.Schedule{}, wrapBridgeError(err)
 	}
 
 	a.mutex.RLock()
+	defer a.mutex.RUnlock()
 
-	name := "Schedule"
 	if updated, ok := a.schedules[schedule.ID]; ok {
-		name = updated.Name
+		return updated, nil
 	}
 
-	a.mutex.RUnlock()
+	schedule.Name = "Schedule"
+	return schedule, nil
+}
+
+func (a *app) handleVacation(w http.ResponseWriter, r *http.Request) {
+	switch r.FormValue("method") {
+	case http.MethodPut:
+		request := vacationRequest{
+			Start:  r.FormValue("start"),
+			End:    r.FormValue("end"),
+			State:  r.FormValue("state"),
+			Groups: r.Form["groups"],
+		}
+
+		if start, end := r.FormValue("windowStart"), r.FormValue("windowEnd"); len(start) != 0 || len(end) != 0 {
+			request.Windows = []vacationWindow{{Start: start, End: end}}
+		}
+
+		output, err := a.startVacation(r.Context(), request)
+		if err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf("Vacation is now set from %s to %s", output.Start.Format(dayFormat), output.End.Format(dayFormat))))
 
-	a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf(updateSuccessMessage, name, status)))
+	case http.MethodDelete:
+		if err := a.stopVacation(r.Context()); err != nil {
+			a.rendererApp.Error(w, err)
+			return
+		}
+
+		a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage("Vacation is now stopped, schedules and rules are restored"))
+
+	default:
+		a.rendererApp.Error(w, model.WrapMethodNotAllowed(fmt.Errorf("invalid method for vacation")))
+	}
 }
 
 func (a *app) handleSensors(w http.ResponseWriter, r *http.Request) {
@@ -126,39 +495,66 @@ func (a *app) handleSensors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sensor := Sensor{
-		ID: strings.Trim(strings.TrimPrefix(r.URL.Path, sensorsPath), "/"),
-		Config: SensorConfig{
-			On: statusBool,
+	sensor, err := a.updateSensorOn(r.Context(), strings.Trim(strings.TrimPrefix(r.URL.Path, sensorsPath), "/"), statusBool)
+	if err != nil {
+		a.rendererApp.Error(w, err)
+		return
+	}
+
+	stateName := "on"
+	if !statusBool {
+		stateName = "off"
+	}
+
+	a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf(updateSuccessMessage, sensor.Name, stateName)))
+}
+
+func (a *app) updateSensorOn(ctx context.Context, sensorID string, on bool) (bridge.Sensor, error) {
+	sensor := bridge.Sensor{
+		ID: sensorID,
+		Config: bridge.SensorConfig{
+			On: on,
 		},
 	}
 
-	if err := a.updateSensorConfig(r.Context(), sensor); err != nil {
-		a.rendererApp.Error(w, err)
-		return
+	if err := a.client.UpdateSensorConfig(ctx, sensor); err != nil {
+		return bridge.Sensor{}, wrapBridgeError(err)
 	}
 
 	if err := a.syncSensors(); err != nil {
-		a.rendererApp.Error(w, err)
-		return
+		return bridge.Sensor{}, wrapBridgeError(err)
 	}
 
 	a.mutex.RLock()
+	defer a.mutex.RUnlock()
 
-	name := "Sensor"
 	for _, s := range a.sensors {
 		if s.ID == sensor.ID {
-			name = s.Name
-			break
+			return s, nil
 		}
 	}
 
-	a.mutex.RUnlock()
+	sensor.Name = "Sensor"
+	return sensor, nil
+}
 
-	stateName := "on"
-	if !statusBool {
-		stateName = "off"
+func wrapBridgeError(err error) error {
+	switch {
+	case errors.Is(err, bridge.ErrUnauthorized):
+		return fmt.Errorf("bridge doesn't know the configured username, pair again with `hue pair`: %w", err)
+	case errors.Is(err, bridge.ErrNotAvailable):
+		return model.WrapNotFound(fmt.Errorf("not available on bridge: %s", err))
+	case errors.Is(err, bridge.ErrInvalidValue):
+		return model.WrapInvalid(fmt.Errorf("rejected by bridge: %s", err))
+	case errors.Is(err, bridge.ErrInternal):
+		return model.WrapInternal(fmt.Errorf("bridge failed: %s", err))
+	default:
+		return err
 	}
+}
 
-	a.rendererApp.Redirect(w, r, "/", renderer.NewSuccessMessage(fmt.Sprintf(updateSuccessMessage, name, stateName)))
+func bridgeUnavailable(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Retry-After", strconv.Itoa(int(connectRetryInterval.Seconds())))
+	http.Error(w, "bridge isn't connected yet, retry in a few seconds", http.StatusServiceUnavailable)
 }
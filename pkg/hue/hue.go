@@ -1,16 +1,18 @@
 package hue
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"flag"
-	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ViBiOh/httputils/v4/pkg/flags"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/hue/pkg/bridge"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -19,13 +21,21 @@ type App interface {
 	Handler() http.Handler
 	TemplateFunc(http.ResponseWriter, *http.Request) (string, int, map[string]interface{}, error)
 	Start(<-chan struct{})
+	Plan(context.Context) ([]string, error)
+	Validate(context.Context) error
 }
 
 // Config of package
 type Config struct {
 	bridgeIP       *string
 	bridgeUsername *string
+	usernameFile   *string
+	bridgeCA       *string
+	bridgePin      *string
+	v1             *bool
+	discoveryURL   *string
 	config         *string
+	adminToken     *string
 }
 
 type app struct {
@@ -33,16 +43,40 @@ type app struct {
 	prometheusCollectors map[string]prometheus.Gauge
 
 	config      *configHue
+	states      map[string]bridge.State
 	apiHandler  http.Handler
 	rendererApp renderer.App
 
-	groups    map[string]Group
-	scenes    map[string]Scene
-	schedules map[string]Schedule
-	sensors   map[string]Sensor
-
-	bridgeURL      string
+	configFile       string
+	configHash       string
+	configFailedHash string
+	configErr        error
+	adminToken       string
+	configMutex      sync.Mutex
+	vacationMutex    sync.Mutex
+
+	groups      map[string]Group
+	lights      map[string]bridge.Light
+	scenes      map[string]bridge.Scene
+	schedules   map[string]bridge.Schedule
+	sensors     map[string]bridge.Sensor
+	sensorNames map[string]string
+	vacation    *vacation
+
+	client         bridge.Client
+	clip           bridge.ClipClient
+	bridgeIP       string
 	bridgeUsername string
+	location       *time.Location
+	usernameFile   string
+	discoveryURL   string
+	bridgeCA       string
+	bridgePin      string
+	v1             bool
+
+	streamListeners map[chan string]struct{}
+	streamState     map[string]string
+	streamMutex     sync.Mutex
 
 	mutex sync.RWMutex
 }
@@ -50,56 +84,110 @@ type app struct {
 // Flags adds flags for configuring package
 func Flags(fs *flag.FlagSet, prefix string) Config {
 	return Config{
-		bridgeIP:       flags.New(prefix, "hue").Name("BridgeIP").Default("").Label("IP of Bridge").ToString(fs),
-		bridgeUsername: flags.New(prefix, "hue").Name("Username").Default("").Label("Username for Bridge").ToString(fs),
+		bridgeIP:       flags.New(prefix, "hue").Name("BridgeIP").Default("").Label("IP of Bridge, discovered if empty").ToString(fs),
+		bridgeUsername: flags.New(prefix, "hue").Name("Username").Default("").Label("Username for Bridge, paired on startup if empty").ToString(fs),
+		usernameFile:   flags.New(prefix, "hue").Name("UsernameFile").Default("").Label("File where username is read, or written after pairing on startup").ToString(fs),
+		bridgeCA:       flags.New(prefix, "hue").Name("BridgeCA").Default("").Label("Hue root CA file for checking bridge certificate").ToString(fs),
+		bridgePin:      flags.New(prefix, "hue").Name("BridgePin").Default("").Label("File storing bridge certificate fingerprint, pinned on first use, if no CA").ToString(fs),
+		v1:             flags.New(prefix, "hue").Name("V1").Default(false).Label("Use legacy v1 API over HTTP, with polling only, for bridges without CLIP v2").ToBool(fs),
+		discoveryURL:   flags.New(prefix, "hue").Name("DiscoveryURL").Default(bridge.DefaultDiscoveryURL).Label("Bridge discovery endpoint, used when mDNS and SSDP fail").ToString(fs),
 		config:         flags.New(prefix, "hue").Name("Config").Default("").Label("Configuration filename").ToString(fs),
+		adminToken:     flags.New(prefix, "hue").Name("AdminToken").Default("").Label("Bearer token for admin endpoints, disabled if empty").ToString(fs),
 	}
 }
 
 // New creates new App from Config
 func New(config Config, registerer prometheus.Registerer, renderer renderer.App) (App, error) {
-	bridgeUsername := strings.TrimSpace(*config.bridgeUsername)
-
 	app := &app{
-		bridgeURL:      fmt.Sprintf("http://%s/api/%s", strings.TrimSpace(*config.bridgeIP), bridgeUsername),
-		bridgeUsername: bridgeUsername,
+		bridgeIP:       strings.TrimSpace(*config.bridgeIP),
+		bridgeUsername: strings.TrimSpace(*config.bridgeUsername),
+		usernameFile:   strings.TrimSpace(*config.usernameFile),
+		discoveryURL:   strings.TrimSpace(*config.discoveryURL),
+		bridgeCA:       strings.TrimSpace(*config.bridgeCA),
+		bridgePin:      strings.TrimSpace(*config.bridgePin),
+		v1:             *config.v1,
 
 		rendererApp: renderer,
+		configFile:  strings.TrimSpace(*config.config),
+		adminToken:  strings.TrimSpace(*config.adminToken),
 
 		prometheusRegisterer: registerer,
 		prometheusCollectors: make(map[string]prometheus.Gauge),
 	}
 
+	if !app.v1 && len(app.bridgeCA) == 0 && len(app.bridgePin) == 0 {
+		return app, errors.New("`-bridgeCA` or `-bridgePin` is required for checking bridge certificate, or use `-v1`")
+	}
+
 	app.apiHandler = http.StripPrefix(apiPath, app.Handler())
 
-	configFile := strings.TrimSpace(*config.config)
-	if len(configFile) != 0 {
-		rawConfig, err := os.ReadFile(configFile)
+	if len(app.configFile) != 0 {
+		rawConfig, err := os.ReadFile(app.configFile)
 		if err != nil {
 			return app, err
 		}
 
-		if err := json.Unmarshal(rawConfig, &app.config); err != nil {
+		if app.config, err = parseConfig(rawConfig); err != nil {
 			return app, err
 		}
+
+		app.configHash = sha(rawConfig)
+		app.states = app.config.lightStates()
+	} else {
+		app.states = States
 	}
 
 	return app, nil
 }
 
 func (a *app) TemplateFunc(w http.ResponseWriter, r *http.Request) (string, int, map[string]interface{}, error) {
+	if !a.isConnected() {
+		bridgeUnavailable(w)
+		return "", 0, nil, nil
+	}
+
 	if strings.HasPrefix(r.URL.Path, apiPath) {
 		a.apiHandler.ServeHTTP(w, r)
 		return "", 0, nil, nil
 	}
 
+	if r.URL.Path == rulesPath {
+		rules, err := a.listRules(r.Context())
+		return "rules", http.StatusOK, map[string]interface{}{"Rules": rules}, err
+	}
+
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
 
+	if strings.HasPrefix(r.URL.Path, scenesPath+"/") {
+		content, err := a.sceneContent(strings.Trim(strings.TrimPrefix(r.URL.Path, scenesPath), "/"))
+		return "scene", http.StatusOK, content, err
+	}
+
+	groupScenes := make(map[string][]bridge.Scene, len(a.groups))
+	activeScenes := make(map[string]string, len(a.groups))
+	for id := range a.groups {
+		groupScenes[id] = a.groupScenes(id)
+		activeScenes[id] = a.activeScene(id)
+	}
+
+	newSchedule, scheduleForms := a.scheduleForms(groupScenes)
+
 	return "public", http.StatusOK, map[string]interface{}{
-		"Groups":    a.groups,
-		"Scenes":    a.scenes,
-		"Schedules": a.schedules,
-		"Sensors":   a.sensors,
+		"NewSchedule":      newSchedule,
+		"ScheduleForms":    scheduleForms,
+		"SolarSchedules":   a.solarSchedules(a.now()),
+		"GroupScenes":      groupScenes,
+		"ActiveScenes":     activeScenes,
+		"Groups":           a.groups,
+		"Lights":           a.lights,
+		"Scenes":           a.scenes,
+		"Schedules":        a.regularSchedules(),
+		"Vacation":         a.vacation,
+		"VacationSwitches": a.vacationSchedules(),
+		"Sensors":          a.sensors,
+		"ConfigError":      a.configErr,
+		"States":           a.states,
+		"CustomStates":     customStateNames(a.states),
 	}, nil
 }
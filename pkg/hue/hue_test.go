@@ -0,0 +1,58 @@
+package hue
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	type args struct {
+		args []string
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		wantErr   string
+	}{
+		{
+			"no certificate check",
+			args{
+				args: nil,
+			},
+			"`-bridgeCA` or `-bridgePin` is required",
+		},
+		{
+			"pinned certificate",
+			args{
+				args: []string{"-bridgePin", "bridge.pin"},
+			},
+			"",
+		},
+		{
+			"legacy API",
+			args{
+				args: []string{"-v1"},
+			},
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			fs := flag.NewFlagSet("hue", flag.ContinueOnError)
+			config := Flags(fs, "")
+
+			if err := fs.Parse(tc.args.args); err != nil {
+				t.Fatalf("Parse() error = %s", err)
+			}
+
+			_, err := New(config, nil, nil)
+
+			if len(tc.wantErr) == 0 && err != nil || len(tc.wantErr) != 0 && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("New() = `%v`, want `%s`", err, tc.wantErr)
+			}
+		})
+	}
+}
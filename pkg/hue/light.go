@@ -2,22 +2,123 @@ package hue
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 
-	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
-	"github.com/ViBiOh/httputils/v4/pkg/request"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
-func (a *app) getLight(ctx context.Context, lightID string) (Light, error) {
-	resp, err := request.New().Get(fmt.Sprintf("%s/lights/%s", a.bridgeURL, lightID)).Send(ctx, nil)
-	if err != nil {
-		return noneLight, err
+func brightnessPercent(bri int) int {
+	return int(math.Round(float64(bri) * 100 / 254))
+}
+
+// lightXY clamps the color to the gamut of the light, the bridge would pick its own approximation otherwise
+func lightXY(light bridge.Light, color bridge.XY) []float64 {
+	xy := light.Gamut().Clamp(color)
+	return []float64{xy[0], xy[1]}
+}
+
+func checkLightState(light bridge.Light, state configState) error {
+	errs := state.validate("")
+
+	if !light.IsDimmable() && state.Bri != nil {
+		errs = append(errs, newConfigError("bri", "light `%s` is not dimmable", light.Name))
+	}
+
+	if state.Ct != nil {
+		if !light.HasCT() {
+			errs = append(errs, newConfigError("ct", "light `%s` has no color temperature", light.Name))
+		} else if ctRange := light.Capabilities.Control.CT; *state.Ct < ctRange.Min || *state.Ct > ctRange.Max {
+			errs = append(errs, newConfigError("ct", "%d is out of range, must be between %d and %d", *state.Ct, ctRange.Min, ctRange.Max))
+		}
+	}
+
+	if (state.Hue != nil || state.Sat != nil || state.XY != nil) && !light.HasColor() {
+		errs = append(errs, newConfigError("color", "light `%s` has no color", light.Name))
+	}
+
+	return joinInvalid(errs)
+}
+
+func joinInvalid(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+	for index, err := range errs {
+		messages[index] = err.Error()
+	}
+
+	return model.WrapInvalid(errors.New(strings.Join(messages, ", ")))
+}
+
+// lightGroups lists groups containing the light
+func (a *app) lightGroups(lightID string) []string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	var output []string
+	for groupID, group := range a.groups {
+		if containsAll(group.Lights, []string{lightID}) {
+			output = append(output, groupID)
+		}
+	}
+
+	return output
+}
+
+func (a *app) updateLightState(ctx context.Context, lightID string, payload configState) (bridge.Light, error) {
+	a.mutex.RLock()
+	light, ok := a.lights[lightID]
+	a.mutex.RUnlock()
+
+	if !ok {
+		return bridge.Light{}, model.WrapNotFound(fmt.Errorf("unknown light '%s'", lightID))
+	}
+
+	if err := checkLightState(light, payload); err != nil {
+		return bridge.Light{}, err
+	}
+
+	state := bridge.State{
+		On:             payload.On,
+		Bri:            payload.Bri,
+		Ct:             payload.Ct,
+		Hue:            payload.Hue,
+		Sat:            payload.Sat,
+		TransitionTime: payload.TransitionTime,
+		Effect:         payload.Effect,
 	}
 
-	var light Light
-	if err := httpjson.Read(resp, &light); err != nil {
-		return noneLight, fmt.Errorf("unable to read light payload: %s", err)
+	if payload.XY != nil {
+		state.XY = lightXY(light, bridge.XY{payload.XY[0], payload.XY[1]})
 	}
 
+	if state.On == nil && (state.Bri != nil || state.Ct != nil || state.Hue != nil || state.Sat != nil || state.XY != nil) {
+		state.On = boolPointer(true)
+	}
+
+	if err := a.client.UpdateLightState(ctx, lightID, state); err != nil {
+		return bridge.Light{}, wrapBridgeError(err)
+	}
+
+	a.overrideMotion(ctx, a.lightGroups(lightID)...)
+
+	light, err := a.client.GetLight(ctx, lightID)
+	if err != nil {
+		return bridge.Light{}, wrapBridgeError(err)
+	}
+
+	a.mutex.Lock()
+	a.lights[lightID] = light
+	a.refreshGroupsState()
+	a.mutex.Unlock()
+
+	a.publishChanges()
+
 	return light, nil
 }
@@ -0,0 +1,105 @@
+package hue
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func TestCheckLightState(t *testing.T) {
+	ambiance := bridge.Light{Name: "Spot", Type: "Color temperature light", Capabilities: bridge.LightCapabilities{Control: bridge.LightControl{CT: &bridge.LightRangeCT{Min: 153, Max: 454}}}}
+	plug := bridge.Light{Name: "Plug", Type: "On/Off plug-in unit"}
+	bulb := bridge.Light{Name: "Bulb", Type: "Extended color light", Capabilities: bridge.LightCapabilities{Control: bridge.LightControl{ColorGamutType: "C"}}}
+
+	type args struct {
+		light bridge.Light
+		state configState
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+	}{
+		{
+			"valid",
+			args{
+				light: ambiance,
+				state: configState{Bri: intPointer(128), Ct: intPointer(300)},
+			},
+			"",
+		},
+		{
+			"out of light range",
+			args{
+				light: ambiance,
+				state: configState{Ct: intPointer(480)},
+			},
+			"ct: 480 is out of range, must be between 153 and 454: invalid",
+		},
+		{
+			"no color",
+			args{
+				light: ambiance,
+				state: configState{XY: []float64{0.3, 0.3}},
+			},
+			"color: light `Spot` has no color: invalid",
+		},
+		{
+			"xy out of range",
+			args{
+				light: bulb,
+				state: configState{XY: []float64{1.2, 0.3}},
+			},
+			"xy[0]: 1.2 is out of range, must be between 0 and 1: invalid",
+		},
+		{
+			"not dimmable",
+			args{
+				light: plug,
+				state: configState{On: boolPointer(true), Bri: intPointer(0)},
+			},
+			"bri: 0 is out of range, must be between 1 and 254, bri: light `Plug` is not dimmable: invalid",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got := ""
+			if err := checkLightState(tc.args.light, tc.args.state); err != nil {
+				got = err.Error()
+			}
+
+			if got != tc.want {
+				t.Errorf("checkLightState() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateLightState(t *testing.T) {
+	_, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "secret"),
+	}
+
+	if err := instance.syncGroups(); err != nil {
+		t.Fatalf("syncGroups() error = %s", err)
+	}
+
+	light, err := instance.updateLightState(context.Background(), "1", configState{XY: []float64{0.1, 0.9}})
+	if err != nil {
+		t.Fatalf("updateLightState() error = %s", err)
+	}
+
+	want := light.Gamut().Clamp(bridge.XY{0.1, 0.9})
+	if got := light.State.XY; !reflect.DeepEqual(got, []float64{want[0], want[1]}) || !light.Gamut().Contains(bridge.XY{got[0], got[1]}) {
+		t.Errorf("updateLightState() = %v, want %v in gamut of the light", got, want)
+	}
+}
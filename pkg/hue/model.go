@@ -1,137 +1,65 @@
 package hue
 
 import (
-	"regexp"
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
 var (
-	// States available states of lights
-	States = map[string]map[string]interface{}{
+	// States default states of lights, completed by the ones declared in configuration
+	States = map[string]bridge.State{
 		"off": {
-			"on":             false,
-			"transitiontime": 30,
+			On:             boolPointer(false),
+			TransitionTime: intPointer(30),
 		},
 		"long_off": {
-			"on":             false,
-			"transitiontime": 300,
+			On:             boolPointer(false),
+			TransitionTime: intPointer(300),
 		},
 		"on": {
-			"on":             true,
-			"transitiontime": 30,
-			"sat":            0,
-			"bri":            255,
+			On:             boolPointer(true),
+			TransitionTime: intPointer(30),
+			Sat:            intPointer(0),
+			Bri:            intPointer(255),
 		},
 		"half": {
-			"on":             true,
-			"transitiontime": 30,
-			"sat":            0,
-			"bri":            96,
+			On:             boolPointer(true),
+			TransitionTime: intPointer(30),
+			Sat:            intPointer(0),
+			Bri:            intPointer(96),
 		},
 		"dimmed": {
-			"on":             true,
-			"transitiontime": 30,
-			"sat":            0,
-			"bri":            0,
+			On:             boolPointer(true),
+			TransitionTime: intPointer(30),
+			Sat:            intPointer(0),
+			Bri:            intPointer(0),
 		},
 		"long_on": {
-			"on":             true,
-			"transitiontime": 3000,
-			"sat":            0,
-			"bri":            255,
+			On:             boolPointer(true),
+			TransitionTime: intPointer(3000),
+			Sat:            intPointer(0),
+			Bri:            intPointer(255),
 		},
 	}
-
-	scheduleGroupFinder = regexp.MustCompile(`(?mi)groups/(.*?)/`)
-
-	noneLight = Light{}
 )
 
-// Group description
+// Group description, enriched for display
 type Group struct {
-	Name   string     `json:"name,omitempty"`
-	Lights []string   `json:"lights,omitempty"`
-	State  groupState `json:"state,omitempty"`
-	Tap    bool       `json:"tap,omitempty"`
-}
-
-type groupState struct {
-	AnyOn bool `json:"any_on"`
-}
-
-// Light description
-type Light struct {
-	Type  string     `json:"type,omitempty"`
-	State lightState `json:"state,omitempty"`
-}
-
-type lightState struct {
-	On bool `json:"on,omitempty"`
-}
-
-// APIScene describe scene as from Hue API
-type APIScene struct {
-	Lightstates map[string]map[string]interface{} `json:"lightstates,omitempty"`
-	Name        string                            `json:"name,omitempty"`
-	Lights      []string                          `json:"lights,omitempty"`
-	Recycle     bool                              `json:"recycle"`
-}
-
-// Scene description
-type Scene struct {
-	ID string `json:"id,omitempty"`
-	APIScene
-}
-
-// Rule description
-type Rule struct {
-	ID         string      `json:"-"`
-	Status     string      `json:"status,omitempty"`
-	Name       string      `json:"name,omitempty"`
-	Actions    []Action    `json:"actions,omitempty"`
-	Conditions []Condition `json:"conditions,omitempty"`
+	bridge.Group
+	Tap bool `json:"tap,omitempty"`
 }
 
-// Sensor description
-type Sensor struct {
-	ID     string       `json:"-"`
-	Name   string       `json:"name,omitempty"`
-	Type   string       `json:"type,omitempty"`
-	State  sensorState  `json:"state,omitempty"`
-	Config SensorConfig `json:"config,omitempty"`
+// ScheduleConfig configuration (made simple)
+type ScheduleConfig struct {
+	Name      string `json:"name"`
+	Localtime string `json:"localtime"`
+	Group     string `json:"group"`
+	State     string `json:"state"`
 }
 
-type sensorState struct {
-	Presence    bool    `json:"presence"`
-	Temperature float32 `json:"temperature,omitempty"`
-}
-
-// SensorConfig description
-type SensorConfig struct {
-	Battery       uint `json:"battery,omitempty"`
-	On            bool `json:"on"`
-	LedIndication bool `json:"ledindication"`
-}
-
-// Action description
-type Action struct {
-	Address string                 `json:"address,omitempty"`
-	Body    map[string]interface{} `json:"body,omitempty"`
-	Method  string                 `json:"method,omitempty"`
-}
-
-// GetGroup returns the group ID of the Action performed
-func (a Action) GetGroup() string {
-	matches := scheduleGroupFinder.FindStringSubmatch(a.Address)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	return ""
+func boolPointer(value bool) *bool {
+	return &value
 }
 
-// Condition description
-type Condition struct {
-	Address  string `json:"address,omitempty"`
-	Operator string `json:"operator,omitempty"`
-	Value    string `json:"value,omitempty"`
+func intPointer(value int) *int {
+	return &value
 }
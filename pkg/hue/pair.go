@@ -0,0 +1,175 @@
+package hue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	discoveryTimeout = time.Second * 15
+	pairTimeout      = time.Minute * 2
+)
+
+// DeviceType returns the device type used for pairing with the bridge
+func DeviceType() string {
+	hostname, err := os.Hostname()
+	if err != nil || len(hostname) == 0 {
+		return "hue"
+	}
+
+	deviceType := fmt.Sprintf("hue#%s", hostname)
+	if len(deviceType) > 40 {
+		return deviceType[:40]
+	}
+
+	return deviceType
+}
+
+func (a *app) connect() error {
+	a.mutex.RLock()
+	connected := !a.client.IsZero()
+	bridgeIP, username := a.bridgeIP, a.bridgeUsername
+	a.mutex.RUnlock()
+
+	if connected {
+		return nil
+	}
+
+	if len(bridgeIP) == 0 {
+		var err error
+		if bridgeIP, err = discoverBridge(a.discoveryURL); err != nil {
+			return err
+		}
+	}
+
+	if len(username) == 0 {
+		var err error
+		if username, err = a.pairBridge(bridgeIP); err != nil {
+			return err
+		}
+	}
+
+	var client bridge.Client
+	var clip bridge.ClipClient
+
+	if a.v1 {
+		client = bridge.New(bridgeIP, username)
+	} else {
+		tlsConfig, err := bridge.NewTLSConfig(a.bridgeCA, a.bridgePin)
+		if err != nil {
+			return err
+		}
+
+		client = bridge.NewSecure(bridgeIP, username, tlsConfig)
+		clip = bridge.NewClip(bridgeIP, username, tlsConfig)
+	}
+
+	location, err := bridgeLocation(client)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.bridgeIP = bridgeIP
+	a.bridgeUsername = username
+	a.location = location
+	a.client = client
+	a.clip = clip
+
+	return nil
+}
+
+// isConnected checks if the bridge client is ready, fields set by connect() are safe to read once it returns true
+func (a *app) isConnected() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return !a.client.IsZero()
+}
+
+// now returns the current time in the timezone of the bridge, where its schedules are evaluated
+func (a *app) now() time.Time {
+	if a.location == nil {
+		return time.Now()
+	}
+
+	return time.Now().In(a.location)
+}
+
+// bridgeLocation loads the timezone configured on the bridge, UTC if none is set
+func bridgeLocation(client bridge.Client) (*time.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+	defer cancel()
+
+	config, err := client.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get bridge config: %s", err)
+	}
+
+	if len(config.Timezone) == 0 || config.Timezone == "none" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load bridge timezone `%s`: %s", config.Timezone, err)
+	}
+
+	return location, nil
+}
+
+func discoverBridge(discoveryURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+	defer cancel()
+
+	bridgeIP, err := bridge.Discover(ctx, discoveryURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to discover bridge: %s", err)
+	}
+
+	logger.Info("Bridge discovered at %s", bridgeIP)
+
+	return bridgeIP, nil
+}
+
+// pairBridge reuses the username stored in usernameFile or pairs with the bridge and stores it there
+func (a *app) pairBridge(bridgeIP string) (string, error) {
+	if len(a.usernameFile) != 0 {
+		if content, err := os.ReadFile(a.usernameFile); err == nil && len(strings.TrimSpace(string(content))) != 0 {
+			return strings.TrimSpace(string(content)), nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("unable to read username file: %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pairTimeout)
+	defer cancel()
+
+	logger.Warn("No username provided, press the link button of the bridge at %s within %s to pair...", bridgeIP, pairTimeout)
+
+	username, err := bridge.Pair(ctx, bridgeIP, DeviceType())
+	if err != nil {
+		return "", err
+	}
+
+	if len(a.usernameFile) == 0 {
+		logger.Warn("Paired with bridge, set `-usernameFile` to keep the username across restarts")
+		return username, nil
+	}
+
+	if err := os.WriteFile(a.usernameFile, []byte(username), 0o600); err != nil {
+		return "", fmt.Errorf("paired with bridge but unable to write username file: %s", err)
+	}
+
+	logger.Info("Paired with bridge, username written to %s", a.usernameFile)
+
+	return username, nil
+}
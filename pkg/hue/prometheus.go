@@ -2,11 +2,16 @@ package hue
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+var (
+	metricNameSanitizer = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
 func (a *app) getMetrics(prefix, suffix string) prometheus.Gauge {
 	name := fmt.Sprintf("%s_%s", prefix, suffix)
 	if gauge, ok := a.prometheusCollectors[name]; ok {
@@ -33,7 +38,9 @@ func (a *app) updatePrometheusSensors() {
 	defer a.mutex.RUnlock()
 
 	for _, sensor := range a.sensors {
-		a.getMetrics(strings.ToLower(sensor.Name), "temperature").Set(float64(sensor.State.Temperature))
-		a.getMetrics(strings.ToLower(sensor.Name), "battery").Set(float64(sensor.Config.Battery))
+		name := metricNameSanitizer.ReplaceAllString(strings.ToLower(sensor.Name), "_")
+
+		a.getMetrics(name, "temperature").Set(float64(sensor.State.Temperature))
+		a.getMetrics(name, "battery").Set(float64(sensor.Config.Battery))
 	}
 }
@@ -0,0 +1,285 @@
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	managedTag     = "hue.json"
+	pendingID      = "(known after apply)"
+	maxNameLength  = 32
+	maxRuleActions = 8
+
+	actionCreate = "create"
+	actionUpdate = "update"
+	actionDelete = "delete"
+
+	kindRule     = "rule"
+	kindScene    = "scene"
+	kindSchedule = "schedule"
+)
+
+type change struct {
+	payload interface{}
+	apply   func(context.Context) error
+	action  string
+	kind    string
+	name    string
+}
+
+func newChange(action, kind, name string, payload interface{}, apply func(context.Context) error) change {
+	return change{
+		action:  action,
+		kind:    kind,
+		name:    name,
+		payload: payload,
+		apply:   apply,
+	}
+}
+
+func (c change) String() string {
+	return fmt.Sprintf("%s %s `%s`", c.action, c.kind, c.name)
+}
+
+func (c change) describe() string {
+	if c.payload == nil {
+		return c.String()
+	}
+
+	payload, err := json.MarshalIndent(c.payload, "  ", "  ")
+	if err != nil {
+		return fmt.Sprintf("%s: %s", c, err)
+	}
+
+	return fmt.Sprintf("%s\n  %s", c, payload)
+}
+
+type bridgeState struct {
+	groups    map[string]Group
+	scenes    map[string]bridge.Scene
+	schedules map[string]bridge.Schedule
+	rules     map[string]bridge.Rule
+	sensors   map[string]bridge.Sensor
+}
+
+func (a *app) fetchBridgeState(ctx context.Context) (state bridgeState, err error) {
+	if state.groups, _, err = a.listGroups(ctx); err != nil {
+		return
+	}
+
+	if state.scenes, err = a.listScenes(ctx); err != nil {
+		return
+	}
+
+	if state.schedules, err = a.client.ListSchedules(ctx); err != nil {
+		return
+	}
+
+	if state.rules, err = a.client.ListRules(ctx); err != nil {
+		return
+	}
+
+	state.sensors, err = a.client.ListSensors(ctx)
+
+	return
+}
+
+func (a *app) plan(state bridgeState, config configHue) ([]change, error) {
+	if errs := config.validateWithBridge(state, a.bridgeUsername); len(errs) > 0 {
+		return nil, configErrors(errs)
+	}
+
+	states := config.lightStates()
+
+	schedules, err := resolveSolarSchedules(config.Schedules, config.Location, a.now())
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := a.configureSchedules(state, states, schedules)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := a.configureTap(state, states, config.Taps, config.Sensors)
+	rules = append(rules, a.configureSwitches(states, config.Switches, config.Sensors)...)
+	rules = append(rules, a.configureMotionSensor(states, config.Sensors)...)
+
+	return append(changes, a.configureRules(state, rules)...), nil
+}
+
+// Plan lists operations needed to reconcile bridge with configuration, without applying them
+func (a *app) Plan(ctx context.Context) ([]string, error) {
+	a.mutex.RLock()
+	config := a.config
+	a.mutex.RUnlock()
+
+	if config == nil {
+		return nil, errors.New("no config provided")
+	}
+
+	if err := a.connect(); err != nil {
+		return nil, err
+	}
+
+	state, err := a.fetchBridgeState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch bridge state: %s", err)
+	}
+
+	changes, err := a.plan(state, *config)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]string, len(changes))
+	for index, item := range changes {
+		output[index] = item.describe()
+	}
+
+	return output, nil
+}
+
+func (a *app) reconcile(ctx context.Context, config configHue) error {
+	state, err := a.fetchBridgeState(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch bridge state: %s", err)
+	}
+
+	changes, err := a.plan(state, config)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, item := range changes {
+		logger.Info("%s", item)
+
+		if err := item.apply(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("unable to %s: %s", item, err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return fmt.Errorf("%w, %d of %d change(s) failed: %s", errPartialReconcile, len(errs), len(changes), model.ConcatError(errs))
+	}
+
+	return nil
+}
+
+func (a *app) configureRules(state bridgeState, rules []bridge.Rule) []change {
+	var changes []change
+
+	existingByName := make(map[string]bridge.Rule)
+	for _, id := range state.ruleIDs() {
+		rule := state.rules[id]
+		if rule.Owner != a.bridgeUsername {
+			continue
+		}
+
+		if _, ok := existingByName[rule.Name]; !ok {
+			existingByName[rule.Name] = rule
+		}
+	}
+
+	kept := make(map[string]bool)
+
+	for _, desired := range rules {
+		rule := desired
+
+		existing, ok := existingByName[rule.Name]
+		if !ok || kept[existing.ID] {
+			changes = append(changes, newChange(actionCreate, kindRule, rule.Name, rule, func(ctx context.Context) error {
+				return a.client.CreateRule(ctx, &rule)
+			}))
+
+			continue
+		}
+
+		kept[existing.ID] = true
+		rule.ID = existing.ID
+
+		if !sameJSON(existing.Conditions, rule.Conditions) || !sameJSON(existing.Actions, rule.Actions) {
+			changes = append(changes, newChange(actionUpdate, kindRule, rule.Name, rule, func(ctx context.Context) error {
+				return a.client.UpdateRule(ctx, rule)
+			}))
+		}
+	}
+
+	for _, id := range state.ruleIDs() {
+		rule := state.rules[id]
+		if kept[id] || rule.Owner != a.bridgeUsername {
+			continue
+		}
+
+		changes = append(changes, newChange(actionDelete, kindRule, rule.Name, nil, func(ctx context.Context) error {
+			return a.client.DeleteRule(ctx, rule.ID)
+		}))
+	}
+
+	return changes
+}
+
+func sameJSON(existing, desired interface{}) bool {
+	return canonicalJSON(existing) == canonicalJSON(desired)
+}
+
+func canonicalJSON(value interface{}) string {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(payload, &generic); err != nil {
+		return ""
+	}
+
+	payload, err = json.Marshal(generic)
+	if err != nil {
+		return ""
+	}
+
+	return string(payload)
+}
+
+func sortedKeys(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
+
+func (s bridgeState) sceneIDs() []string {
+	keys := make([]string, 0, len(s.scenes))
+	for key := range s.scenes {
+		keys = append(keys, key)
+	}
+
+	return sortedKeys(keys)
+}
+
+func (s bridgeState) scheduleIDs() []string {
+	keys := make([]string, 0, len(s.schedules))
+	for key := range s.schedules {
+		keys = append(keys, key)
+	}
+
+	return sortedKeys(keys)
+}
+
+func (s bridgeState) ruleIDs() []string {
+	keys := make([]string, 0, len(s.rules))
+	for key := range s.rules {
+		keys = append(keys, key)
+	}
+
+	return sortedKeys(keys)
+}
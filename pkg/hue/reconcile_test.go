@@ -0,0 +1,351 @@
+package hue
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func changesString(changes []change) []string {
+	output := make([]string, len(changes))
+	for index, item := range changes {
+		output[index] = item.String()
+	}
+
+	return output
+}
+
+func TestConfigureRules(t *testing.T) {
+	instance := &app{bridgeUsername: "secret"}
+
+	onRule := instance.createSensorOnRuleDescription(configSensor{ID: "6", LightSensorID: "7", Groups: []string{"4"}}, configSensorBand{State: "on"}, States)
+	offRule := instance.createSensorOffRuleDescription(configSensor{ID: "6", OffDelay: "PT00:01:00", Groups: []string{"4"}}, States)
+
+	outdatedRule := offRule
+	outdatedRule.ID = "2"
+	outdatedRule.Owner = "secret"
+	outdatedRule.Conditions = outdatedRule.Conditions[:1]
+
+	type args struct {
+		state bridgeState
+		rules []bridge.Rule
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      []string
+	}{
+		{
+			"empty bridge",
+			args{
+				state: bridgeState{},
+				rules: []bridge.Rule{onRule, offRule},
+			},
+			[]string{"create rule `MotionSensor 6 - on`", "create rule `MotionSensor 6 - long_off`"},
+		},
+		{
+			"up to date",
+			args{
+				state: bridgeState{
+					rules: map[string]bridge.Rule{
+						"1": {ID: "1", Owner: "secret", Name: onRule.Name, Conditions: onRule.Conditions, Actions: onRule.Actions},
+					},
+				},
+				rules: []bridge.Rule{onRule},
+			},
+			[]string{},
+		},
+		{
+			"update and delete",
+			args{
+				state: bridgeState{
+					rules: map[string]bridge.Rule{
+						"2": outdatedRule,
+						"3": {ID: "3", Owner: "secret", Name: "Tap 2.1"},
+						"4": {ID: "4", Owner: "official-app", Name: "Living room"},
+					},
+				},
+				rules: []bridge.Rule{offRule},
+			},
+			[]string{"update rule `MotionSensor 6 - long_off`", "delete rule `Tap 2.1`"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := changesString(instance.configureRules(tc.args.state, tc.args.rules)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("configureRules() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigureSchedules(t *testing.T) {
+	instance := &app{bridgeUsername: "secret"}
+
+	groups := map[string]Group{
+		"2": {Group: bridge.Group{Name: "Bedroom", Lights: []string{"1", "2"}}},
+	}
+
+	config := ScheduleConfig{
+		Name:      "Wake Up",
+		Localtime: "W124/T07:55:00",
+		Group:     "2",
+		State:     "long_on",
+	}
+
+	managedScene, _ := instance.sceneFromScheduleConfig(config, groups, States)
+	managedScene.ID = "abc"
+	managedScene.Owner = "secret"
+
+	managedSchedule := instance.scheduleFromConfig(config, "abc")
+	managedSchedule.ID = "1"
+
+	legacyScene := managedScene
+	legacyScene.ID = "ghi"
+	legacyScene.AppData = nil
+
+	legacySchedule := instance.scheduleFromConfig(config, "ghi")
+	legacySchedule.ID = "3"
+	legacySchedule.Description = ""
+
+	type args struct {
+		state     bridgeState
+		schedules []ScheduleConfig
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      []string
+	}{
+		{
+			"empty bridge",
+			args{
+				state: bridgeState{
+					groups: groups,
+				},
+				schedules: []ScheduleConfig{config},
+			},
+			[]string{"create scene `Wake Up`", "create schedule `Wake Up`"},
+		},
+		{
+			"up to date",
+			args{
+				state: bridgeState{
+					groups:    groups,
+					scenes:    map[string]bridge.Scene{"abc": managedScene},
+					schedules: map[string]bridge.Schedule{"1": managedSchedule},
+				},
+				schedules: []ScheduleConfig{config},
+			},
+			[]string{},
+		},
+		{
+			"removed from config",
+			args{
+				state: bridgeState{
+					groups: groups,
+					scenes: map[string]bridge.Scene{
+						"abc": managedScene,
+						"def": {ID: "def", APIScene: bridge.APIScene{Name: "Relax", Owner: "official-app"}},
+					},
+					schedules: map[string]bridge.Schedule{
+						"1": managedSchedule,
+						"2": {ID: "2", APISchedule: bridge.APISchedule{Name: "Official"}},
+					},
+				},
+				schedules: nil,
+			},
+			[]string{"delete schedule `Wake Up`", "delete scene `Wake Up`"},
+		},
+		{
+			"unmanaged with same name",
+			args{
+				state: bridgeState{
+					groups: groups,
+					schedules: map[string]bridge.Schedule{
+						"2": {ID: "2", APISchedule: bridge.APISchedule{Name: "Wake Up"}},
+					},
+				},
+				schedules: []ScheduleConfig{config},
+			},
+			[]string{"create scene `Wake Up`", "create schedule `Wake Up`"},
+		},
+		{
+			"legacy adopted",
+			args{
+				state: bridgeState{
+					groups:    groups,
+					scenes:    map[string]bridge.Scene{"ghi": legacyScene},
+					schedules: map[string]bridge.Schedule{"3": legacySchedule},
+				},
+				schedules: []ScheduleConfig{config},
+			},
+			[]string{"update scene `Wake Up`", "update schedule `Wake Up`"},
+		},
+		{
+			"legacy removed from config",
+			args{
+				state: bridgeState{
+					groups:    groups,
+					scenes:    map[string]bridge.Scene{"ghi": legacyScene},
+					schedules: map[string]bridge.Schedule{"3": legacySchedule},
+				},
+				schedules: nil,
+			},
+			[]string{"delete schedule `Wake Up`", "delete scene `Wake Up`"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got, err := instance.configureSchedules(tc.args.state, States, tc.args.schedules)
+			if err != nil {
+				t.Errorf("configureSchedules() error = %s", err)
+			}
+
+			if output := changesString(got); !reflect.DeepEqual(output, tc.want) {
+				t.Errorf("configureSchedules() = %#v, want %#v", output, tc.want)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	_, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "secret"),
+	}
+
+	config := configHue{
+		Schedules: []ScheduleConfig{{Name: "Wake Up", Localtime: "W124/T07:55:00", Group: "2", State: "long_on"}},
+		Sensors:   []configSensor{{ID: "5", LightSensorID: "6", CompanionID: "9", OffDelay: "PT00:05:00", Groups: []string{"1"}}},
+		Taps:      []configTap{{ID: "8", Buttons: []configTapButton{{ID: "1", State: "half", Groups: []string{"1", "3"}}}}},
+	}
+
+	ctx := context.Background()
+
+	if err := instance.reconcile(ctx, config); err != nil {
+		t.Fatalf("reconcile() error = %s", err)
+	}
+
+	state, err := instance.fetchBridgeState(ctx)
+	if err != nil {
+		t.Fatalf("fetchBridgeState() error = %s", err)
+	}
+
+	changes, err := instance.plan(state, config)
+	if err != nil {
+		t.Fatalf("plan() error = %s", err)
+	}
+
+	if got := changesString(changes); len(got) != 0 {
+		t.Errorf("plan() after reconcile = %#v, want nothing", got)
+	}
+
+	managedScenes, managedSchedules, ownedRules := 0, 0, 0
+	for _, scene := range state.scenes {
+		if isManagedScene(scene) {
+			managedScenes++
+		}
+	}
+	for _, schedule := range state.schedules {
+		if isManagedSchedule(schedule) {
+			managedSchedules++
+		}
+	}
+	for _, rule := range state.rules {
+		if rule.Owner == instance.bridgeUsername {
+			ownedRules++
+		}
+	}
+
+	if ownedRules != 5 || managedSchedules != 1 || managedScenes != 1 {
+		t.Errorf("reconcile() = %d rules, %d schedules, %d scenes, want 5, 1, 1", ownedRules, managedSchedules, managedScenes)
+	}
+
+	if len(state.rules) != ownedRules+1 || len(state.schedules) != managedSchedules+1 {
+		t.Errorf("reconcile() changed rules or schedules of the official app")
+	}
+}
+
+func TestReconcileLegacy(t *testing.T) {
+	_, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "secret"),
+	}
+
+	ctx := context.Background()
+
+	config := configHue{
+		Schedules: []ScheduleConfig{{Name: "Wake Up", Localtime: "W124/T07:55:00", Group: "2", State: "long_on"}},
+	}
+
+	scene := &bridge.Scene{APIScene: bridge.APIScene{Name: "Wake Up", Lights: []string{"3"}, Lightstates: map[string]bridge.State{"3": States["long_on"]}}}
+	if err := instance.client.CreateScene(ctx, scene); err != nil {
+		t.Fatalf("CreateScene() error = %s", err)
+	}
+
+	schedule := instance.scheduleFromConfig(config.Schedules[0], scene.ID)
+	schedule.Description = ""
+	if err := instance.client.CreateSchedule(ctx, &schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %s", err)
+	}
+
+	if err := instance.reconcile(ctx, config); err != nil {
+		t.Fatalf("reconcile() error = %s", err)
+	}
+
+	state, err := instance.fetchBridgeState(ctx)
+	if err != nil {
+		t.Fatalf("fetchBridgeState() error = %s", err)
+	}
+
+	if got := state.schedules[schedule.ID]; !isManagedSchedule(got) || got.Command.Body["scene"] != scene.ID {
+		t.Errorf("reconcile() = %#v, want schedule `%s` adopted", got, schedule.ID)
+	}
+
+	if got := state.scenes[scene.ID]; !isManagedScene(got) {
+		t.Errorf("reconcile() = %#v, want scene `%s` adopted", got, scene.ID)
+	}
+
+	if len(state.schedules) != 2 {
+		t.Errorf("reconcile() = %d schedules, want 2", len(state.schedules))
+	}
+}
+
+func TestReconcileFailure(t *testing.T) {
+	_, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "secret"),
+	}
+
+	groups := []string{"0", "0", "0", "0", "0", "0", "0", "0", "0"}
+
+	config := configHue{
+		Switches: []configSwitch{{ID: "10", Buttons: []configSwitchButton{
+			{Button: "on", Event: "short_release", States: []string{"on"}, Groups: groups},
+			{Button: "off", Event: "short_release", States: []string{"off"}, Groups: []string{"1"}},
+		}}},
+	}
+
+	err := instance.reconcile(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 change(s) failed") || !strings.Contains(err.Error(), "unable to create rule `Switch 10 on short_release`") {
+		t.Errorf("reconcile() = %v, want failure of `Switch 10 on short_release`", err)
+	}
+}
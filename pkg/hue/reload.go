@@ -0,0 +1,164 @@
+package hue
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/httperror"
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+)
+
+const (
+	configWatchInterval = time.Second * 10
+)
+
+var errPartialReconcile = errors.New("bridge partially reconciled")
+
+func sha(content []byte) string {
+	hasher := sha256.New()
+	_, _ = hasher.Write(content)
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func (a *app) watchConfig(done <-chan struct{}) {
+	if len(a.configFile) == 0 {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-signals:
+			logger.Info("SIGHUP received, reloading configuration...")
+			if err := a.reloadConfig(context.Background(), true); err != nil {
+				logger.Error("%s", err)
+			}
+		case <-ticker.C:
+			if err := a.reloadConfig(context.Background(), false); err != nil {
+				logger.Error("%s", err)
+			}
+		}
+	}
+}
+
+func (a *app) reloadConfig(ctx context.Context, force bool) error {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
+	rawConfig, err := os.ReadFile(a.configFile)
+	if err != nil {
+		return a.setConfigError("", fmt.Errorf("unable to read configuration: %s", err))
+	}
+
+	hash := sha(rawConfig)
+	if !force && (hash == a.configHash || hash == a.configFailedHash) {
+		return nil
+	}
+
+	config, err := parseConfig(rawConfig)
+	if err != nil {
+		return a.setConfigError(hash, model.WrapInvalid(err))
+	}
+
+	err = a.reconcile(ctx, *config)
+	if err != nil && !errors.Is(err, errPartialReconcile) {
+		if errors.As(err, &configErrors{}) {
+			err = model.WrapInvalid(err)
+		}
+
+		return a.setConfigError(hash, err)
+	}
+
+	if err != nil {
+		// the bridge is partly on the new configuration: it becomes the current one and failed changes are retried on next check
+		err = fmt.Errorf("configuration reloaded, failed changes will be retried: %w", err)
+		hash = ""
+	}
+
+	a.mutex.Lock()
+	a.config = config
+	a.states = config.lightStates()
+	a.configErr = err
+	a.mutex.Unlock()
+
+	a.configHash = hash
+	a.configFailedHash = ""
+
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Configuration reloaded.")
+
+	return nil
+}
+
+func (a *app) setConfigError(hash string, err error) error {
+	a.configFailedHash = hash
+
+	err = fmt.Errorf("configuration not reloaded, still running on previous one: %w", err)
+
+	a.mutex.Lock()
+	a.configErr = err
+	a.mutex.Unlock()
+
+	return err
+}
+
+func (a *app) isAdmin(r *http.Request) bool {
+	if len(a.adminToken) == 0 {
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.adminToken)) == 1
+}
+
+func (a *app) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !a.isAdmin(r) {
+		httperror.Unauthorized(w, errors.New("invalid admin token"))
+		return
+	}
+
+	if len(a.configFile) == 0 {
+		httperror.BadRequest(w, errors.New("no configuration file to reload"))
+		return
+	}
+
+	if err := a.reloadConfig(context.Background(), true); err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			httperror.BadRequest(w, err)
+		} else {
+			httperror.InternalServerError(w, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
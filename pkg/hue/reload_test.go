@@ -0,0 +1,232 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+const (
+	validConfig   = `{"schedules":[{"name":"Wake Up","localtime":"W124/T07:55:00","group":"2","state":"long_on"}]}`
+	invalidConfig = `{"schedules":[{"name":"Wake Up","localtime":"W124 07:55","group":"2","state":"on"}]}`
+)
+
+func newReloadApp(t *testing.T, content string) (*app, func()) {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), "hue.json")
+	if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("unable to write configuration: %s", err)
+	}
+
+	_, server := fakebridge.NewServer("secret")
+
+	return &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "secret"),
+		configFile:     configFile,
+		config:         &configHue{},
+		adminToken:     "admin",
+	}, server.Close
+}
+
+func TestReloadConfig(t *testing.T) {
+	type args struct {
+		content      string
+		bridgeDown   bool
+		previousHash bool
+	}
+
+	var cases = []struct {
+		intention   string
+		args        args
+		wantErr     bool
+		wantInvalid bool
+		reloaded    bool
+	}{
+		{
+			"unchanged hash",
+			args{content: validConfig, previousHash: true},
+			false,
+			false,
+			false,
+		},
+		{
+			"valid",
+			args{content: validConfig},
+			false,
+			false,
+			true,
+		},
+		{
+			"invalid file",
+			args{content: invalidConfig},
+			true,
+			true,
+			false,
+		},
+		{
+			"bridge failure",
+			args{content: validConfig, bridgeDown: true},
+			true,
+			false,
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			instance, closeBridge := newReloadApp(t, tc.args.content)
+			defer closeBridge()
+
+			if tc.args.previousHash {
+				instance.configHash = sha([]byte(tc.args.content))
+			}
+			if tc.args.bridgeDown {
+				closeBridge()
+			}
+
+			previous := instance.config
+			err := instance.reloadConfig(context.Background(), false)
+
+			if (err != nil) != tc.wantErr || errors.Is(err, model.ErrInvalid) != tc.wantInvalid {
+				t.Errorf("reloadConfig() = `%v`, want error %t, invalid %t", err, tc.wantErr, tc.wantInvalid)
+			}
+
+			if reloaded := instance.config != previous; reloaded != tc.reloaded {
+				t.Errorf("reloadConfig() reloaded = %t, want %t", reloaded, tc.reloaded)
+			}
+
+			if tc.wantErr && (instance.configErr == nil || instance.configFailedHash != sha([]byte(tc.args.content))) {
+				t.Errorf("reloadConfig() didn't record failure, configErr = `%v`", instance.configErr)
+			}
+		})
+	}
+}
+
+func TestReloadConfigPartial(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "hue.json")
+	if err := os.WriteFile(configFile, []byte(validConfig), 0o600); err != nil {
+		t.Fatalf("unable to write configuration: %s", err)
+	}
+
+	failSchedules := true
+	fake := fakebridge.New("secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failSchedules && r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/schedules") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(server), "secret"),
+		configFile:     configFile,
+		config:         &configHue{},
+	}
+
+	previous := instance.config
+	if err := instance.reloadConfig(context.Background(), false); !errors.Is(err, errPartialReconcile) {
+		t.Errorf("reloadConfig() = `%v`, want partial reconcile", err)
+	}
+
+	if instance.config == previous || instance.configErr == nil || len(instance.configHash) != 0 || len(instance.configFailedHash) != 0 {
+		t.Errorf("reloadConfig() didn't keep new configuration for retry, configErr = `%v`", instance.configErr)
+	}
+
+	failSchedules = false
+
+	if err := instance.reloadConfig(context.Background(), false); err != nil {
+		t.Errorf("reloadConfig() retry = `%s`", err)
+	}
+
+	if instance.configErr != nil || instance.configHash != sha([]byte(validConfig)) {
+		t.Errorf("reloadConfig() retry didn't record success, configErr = `%v`", instance.configErr)
+	}
+
+	if count := fake.Count("scenes"); count != 4 {
+		t.Errorf("reloadConfig() retry = %d scenes, want 4", count)
+	}
+}
+
+func TestHandleReload(t *testing.T) {
+	type args struct {
+		method     string
+		token      string
+		content    string
+		bridgeDown bool
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      int
+	}{
+		{
+			"method",
+			args{method: http.MethodGet, token: "admin", content: validConfig},
+			http.StatusMethodNotAllowed,
+		},
+		{
+			"no token",
+			args{method: http.MethodPost, content: validConfig},
+			http.StatusUnauthorized,
+		},
+		{
+			"wrong token",
+			args{method: http.MethodPost, token: "guest", content: validConfig},
+			http.StatusUnauthorized,
+		},
+		{
+			"invalid file",
+			args{method: http.MethodPost, token: "admin", content: invalidConfig},
+			http.StatusBadRequest,
+		},
+		{
+			"bridge failure",
+			args{method: http.MethodPost, token: "admin", content: validConfig, bridgeDown: true},
+			http.StatusInternalServerError,
+		},
+		{
+			"valid",
+			args{method: http.MethodPost, token: "admin", content: validConfig},
+			http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			instance, closeBridge := newReloadApp(t, tc.args.content)
+			defer closeBridge()
+
+			if tc.args.bridgeDown {
+				closeBridge()
+			}
+
+			request := httptest.NewRequest(tc.args.method, "/api/reload", nil)
+			if len(tc.args.token) != 0 {
+				request.Header.Set("Authorization", "Bearer "+tc.args.token)
+			}
+
+			writer := httptest.NewRecorder()
+			instance.handleReload(writer, request)
+
+			if got := writer.Code; got != tc.want {
+				t.Errorf("handleReload() = HTTP/%d, want HTTP/%d", got, tc.want)
+			}
+		})
+	}
+}
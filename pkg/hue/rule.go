@@ -3,39 +3,387 @@ package hue
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	rulesPath = "/rules"
+)
+
+var (
+	attributeNames = map[string]string{
+		"lightlevel":  "light level",
+		"lastupdated": "last update",
+	}
+
+	eventNames = map[int]string{
+		0: "pressed",
+		1: "held",
+		2: "released",
+		3: "released after a long press",
+	}
 )
 
-func (a *app) listRules(ctx context.Context) (map[string]Rule, error) {
-	var response map[string]Rule
-	return response, get(ctx, fmt.Sprintf("%s/rules", a.bridgeURL), &response)
+// ruleDescription is a rule of the bridge, with conditions and actions in plain language
+type ruleDescription struct {
+	ID             string
+	Name           string
+	Owner          string
+	Status         string
+	LastTriggered  string
+	Conditions     []string
+	Actions        []string
+	TimesTriggered int
+	Managed        bool
 }
 
-func (a *app) createRule(ctx context.Context, o *Rule) error {
-	id, err := create(ctx, fmt.Sprintf("%s/rules", a.bridgeURL), o)
+func (r ruleDescription) String() string {
+	return fmt.Sprintf("when %s → %s", strings.Join(r.Conditions, " and "), strings.Join(r.Actions, ", "))
+}
+
+// listRules fetches rules of the bridge and describes them, sorted by name
+func (a *app) listRules(ctx context.Context) ([]ruleDescription, error) {
+	rules, err := a.client.ListRules(ctx)
 	if err != nil {
-		return err
+		return nil, wrapBridgeError(err)
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	output := make([]ruleDescription, 0, len(rules))
+	for _, rule := range rules {
+		output = append(output, a.describeRule(rule))
+	}
+
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Name == output[j].Name {
+			return output[i].ID < output[j].ID
+		}
+		return output[i].Name < output[j].Name
+	})
+
+	return output, nil
+}
+
+// describeRule renders the rule in plain language. Caller must hold the read lock
+func (a *app) describeRule(rule bridge.Rule) ruleDescription {
+	lastTriggered := rule.LastTriggered
+	if len(lastTriggered) == 0 || lastTriggered == "none" {
+		lastTriggered = "never"
+	}
+
+	actions := make([]string, len(rule.Actions))
+	for index, action := range rule.Actions {
+		actions[index] = a.describeAction(action)
+	}
+
+	return ruleDescription{
+		ID:             rule.ID,
+		Name:           rule.Name,
+		Owner:          rule.Owner,
+		Status:         rule.Status,
+		LastTriggered:  lastTriggered,
+		TimesTriggered: rule.TimesTriggered,
+		Conditions:     a.describeConditions(rule.Conditions),
+		Actions:        actions,
+		Managed:        rule.Owner == a.bridgeUsername,
+	}
+}
+
+// describeConditions merges the change operators with the value they watch, e.g. `presence becomes true`
+func (a *app) describeConditions(conditions []bridge.Condition) []string {
+	merged := make(map[int]bool)
+	verbs := make(map[int]string)
+
+	findValue := func(address string) (int, bool) {
+		for index, condition := range conditions {
+			if condition.Operator == "eq" && !merged[index] && (condition.Address == address || strings.HasSuffix(address, "/state/lastupdated") && strings.HasPrefix(condition.Address, strings.TrimSuffix(address, "lastupdated"))) {
+				return index, true
+			}
+		}
+
+		return 0, false
+	}
+
+	for index, condition := range conditions {
+		switch condition.Operator {
+		case "dx":
+			if valueIndex, ok := findValue(condition.Address); ok {
+				merged[index] = true
+				verbs[valueIndex] = "becomes"
+			}
+		case "ddx":
+			if valueIndex, ok := findValue(condition.Address); ok {
+				merged[valueIndex] = true
+				verbs[index] = fmt.Sprintf("has been %s for", a.conditionValue(conditions[valueIndex]))
+			}
+		}
+	}
+
+	var output []string
+
+	for index, condition := range conditions {
+		if merged[index] {
+			continue
+		}
+
+		output = append(output, a.describeCondition(condition, verbs[index]))
+	}
+
+	return output
+}
+
+func (a *app) describeCondition(condition bridge.Condition, verb string) string {
+	subject := a.conditionSubject(condition.Address)
+
+	if strings.HasSuffix(condition.Address, "/state/buttonevent") && condition.Operator == "eq" {
+		return fmt.Sprintf("%s %s", a.conditionSubject(strings.TrimSuffix(condition.Address, "/state/buttonevent")), describeButtonEvent(condition.Value))
+	}
+
+	switch condition.Operator {
+	case "eq":
+		if len(verb) == 0 {
+			verb = "is"
+		}
+
+		return fmt.Sprintf("%s %s %s", subject, verb, a.conditionValue(condition))
+	case "gt":
+		return fmt.Sprintf("%s is above %s", subject, condition.Value)
+	case "lt":
+		return fmt.Sprintf("%s is below %s", subject, condition.Value)
+	case "dx":
+		if strings.HasSuffix(condition.Address, "/state/lastupdated") {
+			return fmt.Sprintf("%s is updated", a.conditionSubject(strings.TrimSuffix(condition.Address, "/state/lastupdated")))
+		}
+
+		return fmt.Sprintf("%s changes", subject)
+	case "ddx":
+		if len(verb) == 0 {
+			verb = "hasn't changed for"
+		}
+
+		return fmt.Sprintf("%s %s %s", subject, verb, describeDuration(condition.Value))
+	case "stable":
+		return fmt.Sprintf("%s is stable", subject)
+	case "not stable":
+		return fmt.Sprintf("%s is changing", subject)
+	case "in", "not in":
+		verb = "is within"
+		if condition.Operator == "not in" {
+			verb = "is outside"
+		}
+
+		return fmt.Sprintf("%s %s %s", subject, verb, describeInterval(condition.Value))
+	default:
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", subject, condition.Operator, condition.Value))
+	}
+}
+
+// conditionSubject names the resource and attribute watched by the address, e.g. `Hallway sensor presence`
+func (a *app) conditionSubject(address string) string {
+	parts := strings.Split(strings.Trim(address, "/"), "/")
+
+	if len(parts) == 2 && parts[0] == "config" && parts[1] == "localtime" {
+		return "time"
+	}
+
+	if len(parts) < 2 {
+		return address
+	}
+
+	var name string
+	switch parts[0] {
+	case "sensors":
+		name = a.sensorName(parts[1])
+	case "groups":
+		name = a.groupName(parts[1])
+		if len(parts) == 4 && (parts[3] == "any_on" || parts[3] == "all_on") {
+			return name
+		}
+	case "lights":
+		name = a.lightName(parts[1])
+	default:
+		return address
+	}
+
+	if len(parts) != 4 {
+		return name
+	}
+
+	attribute := parts[3]
+	if label, ok := attributeNames[attribute]; ok {
+		attribute = label
 	}
 
-	o.ID = id
+	return fmt.Sprintf("%s %s", name, attribute)
+}
 
-	return nil
+func (a *app) conditionValue(condition bridge.Condition) string {
+	switch {
+	case strings.HasSuffix(condition.Address, "/state/any_on"):
+		if condition.Value == "true" {
+			return "on"
+		}
+		return "off"
+	case strings.HasSuffix(condition.Address, "/state/all_on"):
+		if condition.Value == "true" {
+			return "fully on"
+		}
+		return "partly off"
+	default:
+		return condition.Value
+	}
 }
 
-func (a *app) deleteRule(ctx context.Context, id string) error {
-	return remove(ctx, fmt.Sprintf("%s/rules/%s", a.bridgeURL, id))
+// describeButtonEvent decodes button events of Hue Taps, Dimmer Switches and Smart Buttons
+func describeButtonEvent(value string) string {
+	for button, event := range tapButtonMapping {
+		if event == value {
+			return fmt.Sprintf("button %s is pressed", button)
+		}
+	}
+
+	code, err := strconv.Atoi(value)
+	if err != nil || code < 1000 {
+		return fmt.Sprintf("button event is %s", value)
+	}
+
+	event, ok := eventNames[code%1000]
+	if !ok {
+		return fmt.Sprintf("button event is %s", value)
+	}
+
+	return fmt.Sprintf("button %d is %s", code/1000, event)
 }
 
-func (a *app) cleanRules(ctx context.Context) error {
-	rules, err := a.listRules(ctx)
+func describeDuration(value string) string {
+	if !durationRegex.MatchString(value) {
+		return value
+	}
+
+	duration, err := parseClock(strings.TrimPrefix(value, "PT"))
 	if err != nil {
-		return err
+		return value
+	}
+
+	output := duration.String()
+	if strings.HasSuffix(output, "m0s") {
+		output = strings.TrimSuffix(output, "0s")
+	}
+	if strings.HasSuffix(output, "h0m") {
+		output = strings.TrimSuffix(output, "0m")
+	}
+
+	return output
+}
+
+func describeInterval(value string) string {
+	pattern, err := bridge.ParseTimePattern(value)
+	if err != nil || pattern.Kind != bridge.KindInterval {
+		return value
+	}
+
+	if pattern.Days == 0 {
+		return fmt.Sprintf("%s and %s", formatDuration(pattern.Time), formatDuration(pattern.End))
+	}
+
+	return strings.ToLower(pattern.String())
+}
+
+func (a *app) describeAction(action bridge.Action) string {
+	parts := strings.Split(strings.Trim(action.Address, "/"), "/")
+	if action.Method != http.MethodPut || len(parts) < 3 {
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", action.Method, action.Address, describeBody(action.Body)))
+	}
+
+	switch parts[0] {
+	case "groups":
+		if sceneID, ok := action.Body["scene"].(string); ok {
+			return fmt.Sprintf("recall %s in %s", a.sceneName(sceneID), a.groupName(parts[1]))
+		}
+
+		return fmt.Sprintf("set %s to %s", a.groupName(parts[1]), a.describeState(action.Body))
+	case "lights":
+		return fmt.Sprintf("set %s to %s", a.lightName(parts[1]), a.describeState(action.Body))
+	case "sensors":
+		if status, ok := action.Body["status"]; ok && len(action.Body) == 1 {
+			return fmt.Sprintf("set %s status to %v", a.sensorName(parts[1]), status)
+		}
+
+		return fmt.Sprintf("set %s to %s", a.sensorName(parts[1]), describeBody(action.Body))
+	default:
+		return fmt.Sprintf("%s %s %s", action.Method, action.Address, describeBody(action.Body))
+	}
+}
+
+// describeState names the state matching the body, or lists its attributes
+func (a *app) describeState(body map[string]interface{}) string {
+	names := make([]string, 0, len(a.states))
+	for name := range a.states {
+		names = append(names, name)
 	}
 
-	for key := range rules {
-		if err := a.deleteRule(ctx, key); err != nil {
-			return err
+	for _, name := range sortedKeys(names) {
+		if sameJSON(a.states[name].Body(), body) {
+			return name
 		}
 	}
 
-	return nil
+	return describeBody(body)
+}
+
+func describeBody(body map[string]interface{}) string {
+	keys := make([]string, 0, len(body))
+	for key := range body {
+		keys = append(keys, key)
+	}
+
+	attributes := make([]string, len(keys))
+	for index, key := range sortedKeys(keys) {
+		attributes[index] = fmt.Sprintf("%s=%v", key, body[key])
+	}
+
+	return strings.Join(attributes, ", ")
+}
+
+func (a *app) sensorName(id string) string {
+	if name, ok := a.sensorNames[id]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("sensor %s", id)
+}
+
+func (a *app) groupName(id string) string {
+	if group, ok := a.groups[id]; ok {
+		return group.Name
+	}
+
+	if id == allLightsGroup {
+		return "all lights"
+	}
+
+	return fmt.Sprintf("group %s", id)
+}
+
+func (a *app) lightName(id string) string {
+	if light, ok := a.lights[id]; ok {
+		return light.Name
+	}
+
+	return fmt.Sprintf("light %s", id)
+}
+
+func (a *app) sceneName(id string) string {
+	if scene, ok := a.scenes[id]; ok {
+		return scene.Name
+	}
+
+	return fmt.Sprintf("scene %s", id)
 }
@@ -0,0 +1,53 @@
+package hue
+
+import (
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func TestDescribeRule(t *testing.T) {
+	instance := &app{
+		groups:      map[string]Group{"1": {Group: bridge.Group{Name: "Kitchen"}}},
+		scenes:      map[string]bridge.Scene{"abc": {ID: "abc", APIScene: bridge.APIScene{Name: "Relax"}}},
+		sensorNames: map[string]string{"5": "Hallway sensor", "6": "Hallway sensor", "8": "Tap", "9": "Hallway status"},
+		states:      States,
+	}
+
+	var cases = []struct {
+		intention string
+		args      bridge.Rule
+		want      string
+	}{
+		{
+			"motion",
+			instance.createSensorOnRuleDescription(configSensor{ID: "5", LightSensorID: "6", Groups: []string{"1"}}, configSensorBand{Start: "22:00", End: "07:00", State: "on"}, States),
+			"when Hallway sensor presence becomes true and Hallway sensor daylight is false and time is within 22:00:00 and 07:00:00 → set Kitchen to on",
+		},
+		{
+			"no motion",
+			instance.createSensorOffRuleDescription(configSensor{ID: "5", OffDelay: "PT00:05:00", Groups: []string{"1"}}, States),
+			"when Hallway sensor presence has been false for 5m → set Kitchen to long_off",
+		},
+		{
+			"tap cycle",
+			instance.createRuleDescription("8", configTapButton{ID: "2", Scenes: []string{"Relax", "Relax"}, CounterID: "9", Groups: []string{"1"}}, States, bridgeState{
+				scenes: map[string]bridge.Scene{"abc": {ID: "abc", APIScene: bridge.APIScene{Name: "Relax", Type: "GroupScene", Group: "1", Lights: []string{"1"}}}},
+			}, nil)[1],
+			"when Tap button 2 is pressed and Hallway status status is above 0 → recall Relax in Kitchen, set Hallway status status to 0",
+		},
+		{
+			"switch step",
+			instance.createSwitchRulesDescription("10", configSwitchButton{Button: "up", Event: "hold", BrightnessStep: intPointer(10), Groups: []string{"0"}}, States, nil)[0],
+			"when sensor 10 button 2 is held → set all lights to bri_inc=25, transitiontime=4",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			if got := instance.describeRule(tc.args).String(); got != tc.want {
+				t.Errorf("describeRule() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
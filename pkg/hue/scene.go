@@ -2,18 +2,24 @@ package hue
 
 import (
 	"context"
+	"errors"
 	"fmt"
-)
+	"math"
+	"sort"
+	"strings"
 
-func (a *app) listScenes(ctx context.Context) (map[string]Scene, error) {
-	var response map[string]Scene
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
 
-	if err := get(ctx, fmt.Sprintf("%s/scenes", a.bridgeURL), &response); err != nil {
+func (a *app) listScenes(ctx context.Context) (map[string]bridge.Scene, error) {
+	response, err := a.client.ListScenes(ctx)
+	if err != nil {
 		return nil, err
 	}
 
 	for id := range response {
-		scene, err := a.getScene(ctx, id)
+		scene, err := a.client.GetScene(ctx, id)
 		if err != nil {
 			return nil, err
 		}
@@ -24,79 +30,481 @@ func (a *app) listScenes(ctx context.Context) (map[string]Scene, error) {
 	return response, nil
 }
 
-func (a *app) getScene(ctx context.Context, id string) (Scene, error) {
-	var response Scene
-	if err := get(ctx, fmt.Sprintf("%s/scenes/%s", a.bridgeURL, id), &response); err != nil {
-		return response, err
+func (a *app) sceneFromScheduleConfig(config ScheduleConfig, groups map[string]Group, states map[string]bridge.State) (bridge.Scene, error) {
+	group, ok := groups[config.Group]
+	if !ok {
+		return bridge.Scene{}, fmt.Errorf("unknown group id: %s", config.Group)
+	}
+
+	state, ok := states[config.State]
+	if !ok {
+		return bridge.Scene{}, fmt.Errorf("unknown state name: %s", config.State)
 	}
 
-	response.ID = id
+	scene := bridge.Scene{
+		APIScene: bridge.APIScene{
+			Name:        config.Name,
+			Lights:      group.Lights,
+			Lightstates: make(map[string]bridge.State, len(group.Lights)),
+			AppData: &bridge.SceneAppData{
+				Data:    managedTag,
+				Version: 1,
+			},
+			Recycle: false,
+		},
+	}
 
-	return response, nil
+	for _, light := range group.Lights {
+		scene.Lightstates[light] = state
+	}
+
+	return scene, nil
 }
 
-func (a *app) createScene(ctx context.Context, o *Scene) error {
-	id, err := create(ctx, fmt.Sprintf("%s/scenes", a.bridgeURL), o)
-	if err != nil {
-		return err
+func findStateName(schedule bridge.Schedule, scenes map[string]bridge.Scene, states map[string]bridge.State) string {
+	sceneID, ok := schedule.Command.Body["scene"].(string)
+	if !ok {
+		for stateName, state := range states {
+			if sameJSON(state.Body(), schedule.Command.Body) {
+				return stateName
+			}
+		}
+
+		return "unknown"
+	}
+
+	scene, ok := scenes[sceneID]
+	if !ok {
+		return "unknown"
 	}
 
-	o.ID = id
+	if !isManagedScene(scene) {
+		return scene.Name
+	}
 
-	return nil
+	for _, lightState := range scene.Lightstates {
+		for stateName, state := range states {
+			if lightState.String() == state.String() {
+				return stateName
+			}
+		}
+	}
+
+	return "unknown"
 }
 
-func (a *app) createSceneFromScheduleConfig(ctx context.Context, config ScheduleConfig, groups map[string]Group) (Scene, error) {
-	group, ok := groups[config.Group]
+func isManagedScene(scene bridge.Scene) bool {
+	return scene.AppData != nil && scene.AppData.Data == managedTag
+}
+
+// groupScenes lists scenes applying to lights of the group, sorted by name. Caller must hold the read lock
+func (a *app) groupScenes(groupID string) []bridge.Scene {
+	group, ok := a.groups[groupID]
 	if !ok {
-		return Scene{}, fmt.Errorf("unknown group id: %s", config.Group)
+		return nil
+	}
+
+	var scenes []bridge.Scene
+
+	for _, scene := range a.scenes {
+		if isManagedScene(scene) || len(scene.Lights) == 0 {
+			continue
+		}
+
+		if scene.Type == "GroupScene" {
+			if scene.Group == groupID {
+				scenes = append(scenes, scene)
+			}
+
+			continue
+		}
+
+		if containsAll(group.Lights, scene.Lights) {
+			scenes = append(scenes, scene)
+		}
+	}
+
+	sort.Slice(scenes, func(i, j int) bool {
+		return scenes[i].Name < scenes[j].Name
+	})
+
+	return scenes
+}
+
+// activeScene finds the scene of the group matching current state of lights. Caller must hold the read lock
+func (a *app) activeScene(groupID string) string {
+	for _, scene := range a.groupScenes(groupID) {
+		if sceneMatches(scene, a.lights) {
+			return scene.ID
+		}
+	}
+
+	return ""
+}
+
+func sceneMatches(scene bridge.Scene, lights map[string]bridge.Light) bool {
+	if len(scene.Lightstates) == 0 {
+		return false
+	}
+
+	for lightID, state := range scene.Lightstates {
+		light, ok := lights[lightID]
+		if !ok || !lightMatches(state, light.State) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func lightMatches(state bridge.State, current bridge.LightState) bool {
+	if state.On != nil && *state.On != current.On {
+		return false
+	}
+
+	if !current.On {
+		return true
+	}
+
+	if state.Bri != nil && math.Abs(float64(*state.Bri-current.Bri)) > 2 {
+		return false
+	}
+
+	if state.Ct != nil && (current.ColorMode != "ct" || math.Abs(float64(*state.Ct-current.Ct)) > 3) {
+		return false
+	}
+
+	if len(state.XY) == 2 && (current.ColorMode != "xy" || len(current.XY) != 2 || math.Abs(state.XY[0]-current.XY[0]) > 0.01 || math.Abs(state.XY[1]-current.XY[1]) > 0.01) {
+		return false
+	}
+
+	if state.Hue != nil && (current.ColorMode != "hs" || math.Abs(float64(*state.Hue-current.Hue)) > 500) {
+		return false
+	}
+
+	return true
+}
+
+func containsAll(items, values []string) bool {
+	for _, value := range values {
+		found := false
+		for _, item := range items {
+			if item == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *app) recallScene(ctx context.Context, groupID, sceneID string) (Group, error) {
+	a.mutex.RLock()
+	group, ok := a.groups[groupID]
+	found := false
+	for _, scene := range a.groupScenes(groupID) {
+		if scene.ID == sceneID {
+			found = true
+			break
+		}
 	}
+	a.mutex.RUnlock()
 
-	state, ok := States[config.State]
 	if !ok {
-		return Scene{}, fmt.Errorf("unknown state name: %s", config.State)
+		return Group{}, model.WrapNotFound(fmt.Errorf("unknown group '%s'", groupID))
 	}
 
-	scene := Scene{
-		APIScene: APIScene{
-			Name:    config.Name,
-			Lights:  group.Lights,
-			Recycle: false,
-		},
+	if !found {
+		return Group{}, model.WrapNotFound(fmt.Errorf("unknown scene '%s' for group '%s'", sceneID, group.Name))
+	}
+
+	if err := a.client.UpdateGroupAction(ctx, groupID, map[string]string{"scene": sceneID}); err != nil {
+		return Group{}, wrapBridgeError(err)
+	}
+
+	a.overrideMotion(ctx, groupID)
+
+	if err := a.syncGroups(); err != nil {
+		return Group{}, wrapBridgeError(err)
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.groups[groupID], nil
+}
+
+// snapshotState captures the current state of the light, in its color mode
+func snapshotState(light bridge.Light) bridge.State {
+	state := bridge.State{
+		On: boolPointer(light.State.On),
+	}
+
+	if !light.State.On {
+		return state
+	}
+
+	if light.IsDimmable() {
+		state.Bri = intPointer(light.State.Bri)
+	}
+
+	switch light.State.ColorMode {
+	case "ct":
+		state.Ct = intPointer(light.State.Ct)
+	case "xy":
+		state.XY = light.State.XY
+	case "hs":
+		state.Hue = intPointer(light.State.Hue)
+		state.Sat = intPointer(light.State.Sat)
 	}
 
-	if err := a.createScene(ctx, &scene); err != nil {
-		return scene, err
+	return state
+}
+
+func checkSceneName(name string) error {
+	if length := len(strings.TrimSpace(name)); length == 0 || length > maxNameLength {
+		return model.WrapInvalid(fmt.Errorf("name must be between 1 and %d characters", maxNameLength))
 	}
 
-	for _, light := range scene.Lights {
-		if err := a.updateSceneLightState(ctx, scene, light, state); err != nil {
-			return scene, err
+	return nil
+}
+
+// snapshotLights captures state of given lights. Caller must hold the read lock
+func (a *app) snapshotLights(lights []string) map[string]bridge.State {
+	lightstates := make(map[string]bridge.State, len(lights))
+	for _, lightID := range lights {
+		if light, ok := a.lights[lightID]; ok {
+			lightstates[lightID] = snapshotState(light)
 		}
 	}
 
-	return scene, nil
+	return lightstates
+}
+
+func (a *app) createScene(ctx context.Context, groupID, name string) (bridge.Scene, error) {
+	if err := checkSceneName(name); err != nil {
+		return bridge.Scene{}, err
+	}
+
+	if err := a.syncGroups(); err != nil {
+		return bridge.Scene{}, wrapBridgeError(err)
+	}
+
+	a.mutex.RLock()
+	group, ok := a.groups[groupID]
+	lightstates := a.snapshotLights(group.Lights)
+	a.mutex.RUnlock()
+
+	if !ok {
+		return bridge.Scene{}, model.WrapNotFound(fmt.Errorf("unknown group '%s'", groupID))
+	}
+
+	scene := bridge.Scene{
+		APIScene: bridge.APIScene{
+			Name:        strings.TrimSpace(name),
+			Type:        "GroupScene",
+			Group:       groupID,
+			Lightstates: lightstates,
+			Recycle:     false,
+		},
+	}
+
+	if err := a.client.CreateScene(ctx, &scene); err != nil {
+		return bridge.Scene{}, wrapBridgeError(err)
+	}
+
+	return a.refreshScene(ctx, scene.ID)
 }
 
-func (a *app) updateSceneLightState(ctx context.Context, o Scene, lightID string, state map[string]interface{}) error {
-	return update(ctx, fmt.Sprintf("%s/scenes/%s/lightstates/%s", a.bridgeURL, o.ID, lightID), state)
+// editableScene finds a scene that can be changed from the UI, those of the configuration file are reconciled
+func (a *app) editableScene(sceneID string) (bridge.Scene, error) {
+	a.mutex.RLock()
+	scene, ok := a.scenes[sceneID]
+	a.mutex.RUnlock()
+
+	if !ok {
+		return bridge.Scene{}, model.WrapNotFound(fmt.Errorf("unknown scene '%s'", sceneID))
+	}
+
+	if isManagedScene(scene) {
+		return bridge.Scene{}, model.WrapForbidden(fmt.Errorf("scene `%s` is managed by the configuration file", scene.Name))
+	}
+
+	return scene, nil
 }
 
-func (a *app) deleteScene(ctx context.Context, id string) error {
-	return remove(ctx, fmt.Sprintf("%s/scenes/%s", a.bridgeURL, id))
+func (a *app) renameScene(ctx context.Context, sceneID, name string) (bridge.Scene, error) {
+	if _, err := a.editableScene(sceneID); err != nil {
+		return bridge.Scene{}, err
+	}
+
+	if err := checkSceneName(name); err != nil {
+		return bridge.Scene{}, err
+	}
+
+	if err := a.client.RenameScene(ctx, sceneID, strings.TrimSpace(name)); err != nil {
+		return bridge.Scene{}, wrapBridgeError(err)
+	}
+
+	return a.refreshScene(ctx, sceneID)
 }
 
-func (a *app) cleanScenes(ctx context.Context) error {
-	scenes, err := a.listScenes(ctx)
+func (a *app) captureScene(ctx context.Context, sceneID string) (bridge.Scene, error) {
+	scene, err := a.editableScene(sceneID)
 	if err != nil {
-		return err
+		return bridge.Scene{}, err
+	}
+
+	if err := a.syncGroups(); err != nil {
+		return bridge.Scene{}, wrapBridgeError(err)
 	}
 
-	for key := range scenes {
-		if err := a.deleteScene(ctx, key); err != nil {
-			return err
+	a.mutex.RLock()
+	lightstates := a.snapshotLights(scene.Lights)
+	a.mutex.RUnlock()
+
+	for lightID, state := range lightstates {
+		if err := a.client.UpdateSceneLightState(ctx, sceneID, lightID, state); err != nil {
+			return bridge.Scene{}, wrapBridgeError(err)
 		}
 	}
 
-	return nil
+	return a.refreshScene(ctx, sceneID)
+}
+
+func (a *app) updateSceneLightState(ctx context.Context, sceneID, lightID string, adjust adjustment) (bridge.Scene, error) {
+	scene, err := a.editableScene(sceneID)
+	if err != nil {
+		return bridge.Scene{}, err
+	}
+
+	if !containsAll(scene.Lights, []string{lightID}) {
+		return bridge.Scene{}, model.WrapNotFound(fmt.Errorf("unknown light '%s' in scene `%s`", lightID, scene.Name))
+	}
+
+	if len(adjust.State) != 0 || len(adjust.Scene) != 0 || adjust.BrightnessStep != nil || adjust.MirekStep != nil {
+		return bridge.Scene{}, model.WrapInvalid(errors.New("only values can be stored in a scene, without state, scene or steps"))
+	}
+
+	if err := adjust.validate(); err != nil {
+		return bridge.Scene{}, err
+	}
+
+	a.mutex.RLock()
+	light := a.lights[lightID]
+	a.mutex.RUnlock()
+
+	state := adjust.lightState(light)
+	if state.On == nil {
+		state.On = boolPointer(true)
+	}
+
+	if err := a.client.UpdateSceneLightState(ctx, sceneID, lightID, state); err != nil {
+		return bridge.Scene{}, wrapBridgeError(err)
+	}
+
+	return a.refreshScene(ctx, sceneID)
+}
+
+func (a *app) deleteScene(ctx context.Context, sceneID string) (bridge.Scene, error) {
+	scene, err := a.editableScene(sceneID)
+	if err != nil {
+		return bridge.Scene{}, err
+	}
+
+	if err := a.client.DeleteScene(ctx, sceneID); err != nil {
+		return bridge.Scene{}, wrapBridgeError(err)
+	}
+
+	a.mutex.Lock()
+	delete(a.scenes, sceneID)
+	a.mutex.Unlock()
+
+	a.publishChanges()
+
+	return scene, nil
+}
+
+func (a *app) refreshScene(ctx context.Context, sceneID string) (bridge.Scene, error) {
+	scene, err := a.client.GetScene(ctx, sceneID)
+	if err != nil {
+		return bridge.Scene{}, wrapBridgeError(err)
+	}
+
+	a.mutex.Lock()
+	if a.scenes == nil {
+		a.scenes = make(map[string]bridge.Scene)
+	}
+	a.scenes[sceneID] = scene
+	a.mutex.Unlock()
+
+	a.publishChanges()
+
+	return scene, nil
+}
+
+type sceneLight struct {
+	bridge.Light
+	ID         string
+	On         bool
+	Brightness int
+	Kelvin     int
+	Color      string
+}
+
+func newSceneLight(id string, light bridge.Light, state bridge.State) sceneLight {
+	output := sceneLight{
+		Light:      light,
+		ID:         id,
+		On:         state.On == nil || *state.On,
+		Brightness: 100,
+		Kelvin:     2700,
+		Color:      "#ffb060",
+	}
+
+	if state.Bri != nil {
+		output.Brightness = brightnessPercent(*state.Bri)
+	}
+
+	if state.Ct != nil {
+		output.Kelvin = mirekToKelvin(*state.Ct)
+	}
+
+	if len(state.XY) == 2 {
+		red, green, blue := bridge.XYToRGB(bridge.XY{state.XY[0], state.XY[1]})
+		output.Color = fmt.Sprintf("#%02x%02x%02x", red, green, blue)
+	}
+
+	return output
+}
+
+// sceneContent gathers content of the scene page. Caller must hold the read lock
+func (a *app) sceneContent(sceneID string) (map[string]interface{}, error) {
+	scene, ok := a.scenes[sceneID]
+	if !ok {
+		return nil, model.WrapNotFound(fmt.Errorf("unknown scene '%s'", sceneID))
+	}
+
+	lights := make([]sceneLight, 0, len(scene.Lights))
+	for _, lightID := range scene.Lights {
+		lights = append(lights, newSceneLight(lightID, a.lights[lightID], scene.Lightstates[lightID]))
+	}
+
+	content := map[string]interface{}{
+		"Scene":   scene,
+		"Managed": isManagedScene(scene),
+		"Lights":  lights,
+	}
+
+	if group, ok := a.groups[scene.Group]; ok {
+		content["GroupID"] = scene.Group
+		content["Group"] = group
+	}
+
+	return content, nil
 }
@@ -0,0 +1,139 @@
+package hue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func TestActiveScene(t *testing.T) {
+	scenes := map[string]bridge.Scene{
+		"relax": {ID: "relax", APIScene: bridge.APIScene{Name: "Relax", Type: "GroupScene", Group: "1", Lights: []string{"1", "2"}, Lightstates: map[string]bridge.State{
+			"1": {On: boolPointer(true), Bri: intPointer(144), Ct: intPointer(447)},
+			"2": {On: boolPointer(true), Bri: intPointer(144), Ct: intPointer(447)},
+		}}},
+		"reading": {ID: "reading", APIScene: bridge.APIScene{Name: "Reading", Type: "LightScene", Lights: []string{"1"}, Lightstates: map[string]bridge.State{
+			"1": {On: boolPointer(true), Bri: intPointer(254), XY: []float64{0.4448, 0.4066}},
+		}}},
+		"managed": {ID: "managed", APIScene: bridge.APIScene{Name: "Wake Up", Lights: []string{"1", "2"}, AppData: &bridge.SceneAppData{Data: managedTag}, Lightstates: map[string]bridge.State{
+			"1": {On: boolPointer(false)},
+			"2": {On: boolPointer(false)},
+		}}},
+	}
+
+	type args struct {
+		lights map[string]bridge.Light
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+	}{
+		{
+			"group scene",
+			args{
+				lights: map[string]bridge.Light{
+					"1": {State: bridge.LightState{On: true, Bri: 145, Ct: 447, ColorMode: "ct"}},
+					"2": {State: bridge.LightState{On: true, Bri: 144, Ct: 445, ColorMode: "ct"}},
+				},
+			},
+			"relax",
+		},
+		{
+			"light scene",
+			args{
+				lights: map[string]bridge.Light{
+					"1": {State: bridge.LightState{On: true, Bri: 254, XY: []float64{0.4452, 0.4061}, ColorMode: "xy"}},
+					"2": {State: bridge.LightState{On: false}},
+				},
+			},
+			"reading",
+		},
+		{
+			"managed scene ignored",
+			args{
+				lights: map[string]bridge.Light{
+					"1": {State: bridge.LightState{On: false}},
+					"2": {State: bridge.LightState{On: false}},
+				},
+			},
+			"",
+		},
+		{
+			"color mode differs",
+			args{
+				lights: map[string]bridge.Light{
+					"1": {State: bridge.LightState{On: true, Bri: 144, Ct: 447, ColorMode: "xy"}},
+					"2": {State: bridge.LightState{On: true, Bri: 144, Ct: 447, ColorMode: "ct"}},
+				},
+			},
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			instance := &app{
+				groups: map[string]Group{"1": {Group: bridge.Group{Lights: []string{"1", "2"}}}},
+				lights: tc.args.lights,
+				scenes: scenes,
+			}
+
+			if got := instance.activeScene("1"); got != tc.want {
+				t.Errorf("activeScene() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotState(t *testing.T) {
+	type args struct {
+		light bridge.Light
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+	}{
+		{
+			"off",
+			args{
+				light: bridge.Light{Type: "Extended color light", State: bridge.LightState{On: false, Bri: 254, ColorMode: "ct", Ct: 366}},
+			},
+			`{"on":false}`,
+		},
+		{
+			"plug",
+			args{
+				light: bridge.Light{Type: "On/Off plug-in unit", State: bridge.LightState{On: true}},
+			},
+			`{"on":true}`,
+		},
+		{
+			"white",
+			args{
+				light: bridge.Light{Type: "Color temperature light", State: bridge.LightState{On: true, Bri: 127, ColorMode: "ct", Ct: 366}},
+			},
+			`{"on":true,"bri":127,"ct":366}`,
+		},
+		{
+			"color",
+			args{
+				light: bridge.Light{Type: "Extended color light", State: bridge.LightState{On: true, Bri: 254, ColorMode: "xy", Ct: 366, XY: []float64{0.5, 0.4}}},
+			},
+			`{"on":true,"bri":254,"xy":[0.5,0.4]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			payload, _ := json.Marshal(snapshotState(tc.args.light))
+			if got := string(payload); got != tc.want {
+				t.Errorf("snapshotState() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
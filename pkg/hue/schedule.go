@@ -5,109 +5,527 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
-func (a *app) listSchedules(ctx context.Context) (map[string]Schedule, error) {
-	var response map[string]Schedule
+func (a *app) scheduleFromConfig(config ScheduleConfig, sceneID string) bridge.Schedule {
+	return bridge.Schedule{
+		APISchedule: bridge.APISchedule{
+			Name:        config.Name,
+			Description: managedTag,
+			Localtime:   config.Localtime,
+			Command: bridge.Action{
+				Address: fmt.Sprintf("/api/%s/groups/%s/action", a.bridgeUsername, config.Group),
+				Body: map[string]interface{}{
+					"scene": sceneID,
+				},
+				Method: http.MethodPut,
+			},
+		},
+	}
+}
+
+func (a *app) configureSchedules(state bridgeState, states map[string]bridge.State, schedules []ScheduleConfig) ([]change, error) {
+	var changes []change
+
+	existingByName := make(map[string]bridge.Schedule)
+	for _, id := range state.scheduleIDs() {
+		schedule := state.schedules[id]
+		if _, ok := existingByName[schedule.Name]; !ok && schedule.Description == managedTag {
+			existingByName[schedule.Name] = schedule
+		}
+	}
+
+	legacyScenes := make(map[string]bool)
+	for _, id := range state.scheduleIDs() {
+		schedule := state.schedules[id]
+		if !isLegacySchedule(state, schedule, a.bridgeUsername) {
+			continue
+		}
+
+		legacyScenes[schedule.Command.Body["scene"].(string)] = true
+		if _, ok := existingByName[schedule.Name]; !ok {
+			existingByName[schedule.Name] = schedule
+		}
+	}
+
+	keptSchedules := make(map[string]bool)
+	keptScenes := make(map[string]bool)
+
+	for _, config := range schedules {
+		desiredScene, err := a.sceneFromScheduleConfig(config, state.groups, states)
+		if err != nil {
+			return nil, err
+		}
+
+		scene := &desiredScene
+		desired := a.scheduleFromConfig(config, "")
 
-	if err := get(ctx, fmt.Sprintf("%s/schedules", a.bridgeURL), &response); err != nil {
-		return nil, err
+		existing, found := existingByName[config.Name]
+		if found {
+			keptSchedules[existing.ID] = true
+
+			if sceneID, ok := existing.Command.Body["scene"].(string); ok {
+				if existingScene, ok := state.scenes[sceneID]; ok && existingScene.Owner == a.bridgeUsername {
+					keptScenes[sceneID] = true
+					scene.ID = sceneID
+
+					if !sameScene(existingScene, desiredScene) {
+						changes = append(changes, newChange(actionUpdate, kindScene, config.Name, desiredScene.APIScene, func(ctx context.Context) error {
+							return a.client.UpdateScene(ctx, *scene)
+						}))
+					}
+				}
+			}
+		}
+
+		if len(scene.ID) == 0 {
+			desired.Command.Body["scene"] = pendingID
+
+			changes = append(changes, newChange(actionCreate, kindScene, config.Name, desiredScene.APIScene, func(ctx context.Context) error {
+				return a.client.CreateScene(ctx, scene)
+			}))
+		}
+
+		if !found {
+			changes = append(changes, newChange(actionCreate, kindSchedule, config.Name, desired.APISchedule, func(ctx context.Context) error {
+				desired.Command.Body["scene"] = scene.ID
+				return a.client.CreateSchedule(ctx, &desired)
+			}))
+
+			continue
+		}
+
+		desired.ID = existing.ID
+		if len(scene.ID) != 0 {
+			desired.Command.Body["scene"] = scene.ID
+		}
+
+		if len(scene.ID) == 0 || !sameSchedule(existing, desired) {
+			changes = append(changes, newChange(actionUpdate, kindSchedule, config.Name, desired.APISchedule, func(ctx context.Context) error {
+				desired.Command.Body["scene"] = scene.ID
+				return a.client.UpdateSchedule(ctx, desired)
+			}))
+		}
 	}
 
-	output := make(map[string]Schedule, len(response))
-	for id, schedule := range response {
-		schedule.ID = id
-		output[id] = schedule
+	for _, id := range state.scheduleIDs() {
+		schedule := state.schedules[id]
+		if keptSchedules[id] || (schedule.Description != managedTag && !isLegacySchedule(state, schedule, a.bridgeUsername)) {
+			continue
+		}
+
+		changes = append(changes, newChange(actionDelete, kindSchedule, schedule.Name, nil, func(ctx context.Context) error {
+			return a.client.DeleteSchedule(ctx, schedule.ID)
+		}))
 	}
 
-	return output, nil
+	for _, id := range state.sceneIDs() {
+		scene := state.scenes[id]
+		if keptScenes[id] || (!isManagedScene(scene) && !legacyScenes[id]) {
+			continue
+		}
+
+		changes = append(changes, newChange(actionDelete, kindScene, scene.Name, nil, func(ctx context.Context) error {
+			return a.client.DeleteScene(ctx, scene.ID)
+		}))
+	}
+
+	return changes, nil
 }
 
-func (a *app) createSchedule(ctx context.Context, o *Schedule) error {
-	id, err := create(ctx, fmt.Sprintf("%s/schedules", a.bridgeURL), o)
-	if err != nil {
-		return err
+func sameSchedule(existing, desired bridge.Schedule) bool {
+	return existing.Name == desired.Name &&
+		existing.Description == desired.Description &&
+		existing.Localtime == desired.Localtime &&
+		sameJSON(existing.Command, desired.Command)
+}
+
+func sameScene(existing, desired bridge.Scene) bool {
+	if existing.Name != desired.Name || existing.AppData == nil || existing.AppData.Data != managedTag {
+		return false
 	}
 
-	o.ID = id
+	if len(existing.Lights) != len(desired.Lights) || len(existing.Lightstates) != len(desired.Lightstates) {
+		return false
+	}
+
+	existingLights := make([]string, len(existing.Lights))
+	copy(existingLights, existing.Lights)
+	sort.Strings(existingLights)
+
+	desiredLights := make([]string, len(desired.Lights))
+	copy(desiredLights, desired.Lights)
+	sort.Strings(desiredLights)
+
+	for index, light := range existingLights {
+		if desiredLights[index] != light {
+			return false
+		}
+	}
+
+	for light, state := range desired.Lightstates {
+		existingState, ok := existing.Lightstates[light]
+		if !ok || existingState.String() != state.String() {
+			return false
+		}
+	}
+
+	return true
+}
 
-	return nil
+const (
+	clockFormat = "15:04:05"
+	dateFormat  = "2006-01-02T15:04:05"
+)
+
+type scheduleRequest struct {
+	Name   string             `json:"name"`
+	Group  string             `json:"group"`
+	State  string             `json:"state,omitempty"`
+	Scene  string             `json:"scene,omitempty"`
+	Kind   bridge.PatternKind `json:"kind"`
+	Days   []string           `json:"days,omitempty"`
+	Time   string             `json:"time"`
+	Random string             `json:"random,omitempty"`
+	Status string             `json:"status,omitempty"`
 }
 
-func (a *app) createScheduleFromConfig(ctx context.Context, config ScheduleConfig, groups map[string]Group) error {
-	if groups == nil {
-		var err error
+func parseClock(value string) (time.Duration, error) {
+	if len(value) == len("15:04") {
+		value += ":00"
+	}
+
+	clock, err := time.Parse(clockFormat, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute + time.Duration(clock.Second())*time.Second, nil
+}
+
+// localtime converts the request to a time pattern of the bridge
+func (s scheduleRequest) localtime(now time.Time) (string, error) {
+	pattern := bridge.TimePattern{
+		Kind: s.Kind,
+	}
+
+	var err error
 
-		if groups, err = a.listGroups(ctx); err != nil {
-			return err
+	if len(s.Random) != 0 {
+		if pattern.Random, err = parseClock(s.Random); err != nil {
+			return "", fmt.Errorf("invalid randomization `%s`, expected HH:MM:SS", s.Random)
 		}
 	}
 
-	scene, err := a.createSceneFromScheduleConfig(ctx, config, groups)
+	switch s.Kind {
+	case bridge.KindRecurring:
+		if pattern.Days, err = bridge.ParseRecurrence(s.Days); err != nil {
+			return "", err
+		}
+
+		if pattern.Time, err = parseClock(s.Time); err != nil {
+			return "", fmt.Errorf("invalid time `%s`, expected HH:MM", s.Time)
+		}
+
+	case bridge.KindAbsolute:
+		value := s.Time
+		if len(value) == len("2006-01-02T15:04") {
+			value += ":00"
+		}
+
+		date, err := time.ParseInLocation(dateFormat, value, now.Location())
+		if err != nil {
+			return "", fmt.Errorf("invalid time `%s`, expected YYYY-MM-DDTHH:MM", s.Time)
+		}
+
+		if !date.After(now) {
+			return "", fmt.Errorf("time `%s` is in the past", s.Time)
+		}
+
+		pattern.Date = date
+
+	case bridge.KindTimer:
+		if pattern.Time, err = parseClock(s.Time); err != nil || pattern.Time == 0 {
+			return "", fmt.Errorf("invalid duration `%s`, expected HH:MM:SS", s.Time)
+		}
+
+	default:
+		return "", fmt.Errorf("unknown kind `%s`, must be %s, %s or %s", s.Kind, bridge.KindRecurring, bridge.KindAbsolute, bridge.KindTimer)
+	}
+
+	return pattern.Pattern(), nil
+}
+
+// newScheduleRequest describes an existing schedule, for editing it
+func newScheduleRequest(schedule bridge.Schedule) scheduleRequest {
+	request := scheduleRequest{
+		Name:   schedule.Name,
+		Group:  schedule.Command.GetGroup(),
+		Status: schedule.Status,
+	}
+
+	if sceneID, ok := schedule.Command.Body["scene"].(string); ok {
+		request.Scene = sceneID
+	}
+
+	pattern, err := bridge.ParseTimePattern(schedule.Localtime)
 	if err != nil {
-		return err
+		return request
 	}
 
-	schedule := &Schedule{
-		APISchedule: APISchedule{
-			Name:      config.Name,
-			Localtime: config.Localtime,
-			Command: Action{
-				Address: fmt.Sprintf("/api/%s/groups/%s/action", a.bridgeUsername, config.Group),
-				Body: map[string]interface{}{
-					"scene": scene.ID,
-				},
-				Method: http.MethodPut,
+	request.Kind = pattern.Kind
+	request.Days = bridge.RecurrenceDays(pattern.Days)
+	request.Time = formatDuration(pattern.Time)
+
+	if pattern.Kind == bridge.KindAbsolute {
+		request.Time = pattern.Date.Format(dateFormat)
+	}
+
+	if pattern.Random != 0 {
+		request.Random = formatDuration(pattern.Random)
+	}
+
+	return request
+}
+
+func formatDuration(duration time.Duration) string {
+	return time.Time{}.Add(duration).Format(clockFormat)
+}
+
+func isManagedSchedule(schedule bridge.Schedule) bool {
+	return schedule.Description == managedTag
+}
+
+// isLegacySchedule detects schedules created before the managed tag existed: no description and recalling a scene of the app, they are adopted and tagged on reconcile
+func isLegacySchedule(state bridgeState, schedule bridge.Schedule, username string) bool {
+	if len(schedule.Description) != 0 {
+		return false
+	}
+
+	sceneID, ok := schedule.Command.Body["scene"].(string)
+	if !ok {
+		return false
+	}
+
+	scene, ok := state.scenes[sceneID]
+	return ok && scene.Owner == username && !isManagedScene(scene)
+}
+
+// scheduleFromRequest builds the schedule to store on the bridge, checking its group, state and scene
+func (a *app) scheduleFromRequest(request scheduleRequest, now time.Time) (bridge.Schedule, error) {
+	var errs []error
+
+	name := strings.TrimSpace(request.Name)
+	if len(name) == 0 || len(name) > maxNameLength {
+		errs = append(errs, fmt.Errorf("name must be between 1 and %d characters", maxNameLength))
+	}
+
+	localtime, err := request.localtime(now)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if a.config != nil {
+		for _, config := range a.config.Schedules {
+			if config.Name == name {
+				errs = append(errs, fmt.Errorf("name `%s` is used by the configuration file", name))
+			}
+		}
+	}
+
+	body := make(map[string]interface{})
+
+	if _, ok := a.groups[request.Group]; !ok {
+		errs = append(errs, fmt.Errorf("unknown group '%s'", request.Group))
+	}
+
+	switch {
+	case len(request.State) != 0 && len(request.Scene) != 0:
+		errs = append(errs, errors.New("state and scene can't be combined"))
+
+	case len(request.State) != 0:
+		state, ok := a.states[request.State]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown state '%s'", request.State))
+		}
+		body = state.Body()
+
+	case len(request.Scene) != 0:
+		found := false
+		for _, scene := range a.groupScenes(request.Group) {
+			found = found || scene.ID == request.Scene
+		}
+
+		if !found {
+			errs = append(errs, fmt.Errorf("unknown scene '%s' for group '%s'", request.Scene, request.Group))
+		}
+		body["scene"] = request.Scene
+
+	default:
+		errs = append(errs, errors.New("state or scene is required"))
+	}
+
+	if len(errs) != 0 {
+		return bridge.Schedule{}, joinInvalid(errs)
+	}
+
+	return bridge.Schedule{
+		APISchedule: bridge.APISchedule{
+			Name:      name,
+			Localtime: localtime,
+			Command: bridge.Action{
+				Address: fmt.Sprintf("/api/%s/groups/%s/action", a.bridgeUsername, request.Group),
+				Body:    body,
+				Method:  http.MethodPut,
 			},
+			Status: request.Status,
 		},
+	}, nil
+}
+
+// editableSchedule finds a schedule that can be changed from the UI, those of the configuration file are reconciled
+func (a *app) editableSchedule(scheduleID string) (bridge.Schedule, error) {
+	a.mutex.RLock()
+	schedule, ok := a.schedules[scheduleID]
+	a.mutex.RUnlock()
+
+	if !ok {
+		return bridge.Schedule{}, model.WrapNotFound(fmt.Errorf("unknown schedule '%s'", scheduleID))
 	}
 
-	if err := a.createSchedule(ctx, schedule); err != nil {
-		return err
+	if isManagedSchedule(schedule) {
+		return bridge.Schedule{}, model.WrapForbidden(fmt.Errorf("schedule `%s` is managed by the configuration file", schedule.Name))
 	}
 
-	return nil
+	if isVacationSchedule(schedule) {
+		return bridge.Schedule{}, model.WrapForbidden(fmt.Errorf("schedule `%s` is programmed by the vacation mode", schedule.Name))
+	}
+
+	return schedule, nil
 }
 
-func (a *app) updateSchedule(ctx context.Context, schedule Schedule) error {
-	if schedule.ID == "" {
-		return errors.New("missing schedule ID to update")
+func (a *app) createSchedule(ctx context.Context, request scheduleRequest) (bridge.Schedule, error) {
+	schedule, err := a.scheduleFromRequest(request, a.now())
+	if err != nil {
+		return bridge.Schedule{}, err
 	}
 
-	return update(ctx, fmt.Sprintf("%s/schedules/%s", a.bridgeURL, schedule.ID), schedule.APISchedule)
-}
+	if err := a.client.CreateSchedule(ctx, &schedule); err != nil {
+		return bridge.Schedule{}, wrapBridgeError(err)
+	}
 
-func (a *app) deleteSchedule(ctx context.Context, id string) error {
-	return remove(ctx, fmt.Sprintf("%s/schedules/%s", a.bridgeURL, id))
+	return a.refreshSchedule(schedule)
 }
 
-func (a *app) cleanSchedules(ctx context.Context) error {
-	schedules, err := a.listSchedules(ctx)
+func (a *app) updateSchedule(ctx context.Context, scheduleID string, request scheduleRequest) (bridge.Schedule, error) {
+	if _, err := a.editableSchedule(scheduleID); err != nil {
+		return bridge.Schedule{}, err
+	}
+
+	schedule, err := a.scheduleFromRequest(request, a.now())
 	if err != nil {
-		return err
+		return bridge.Schedule{}, err
 	}
 
-	for key := range schedules {
-		if err := a.deleteSchedule(ctx, key); err != nil {
-			return err
-		}
+	schedule.ID = scheduleID
+
+	if err := a.client.UpdateSchedule(ctx, schedule); err != nil {
+		return bridge.Schedule{}, wrapBridgeError(err)
 	}
 
-	return nil
+	return a.refreshSchedule(schedule)
 }
 
-func (a *app) configureSchedules(ctx context.Context, schedules []ScheduleConfig) {
-	groups, err := a.listGroups(ctx)
+func (a *app) deleteSchedule(ctx context.Context, scheduleID string) (bridge.Schedule, error) {
+	schedule, err := a.editableSchedule(scheduleID)
 	if err != nil {
-		logger.Error("%s", err)
-		return
+		return bridge.Schedule{}, err
 	}
 
-	for _, config := range schedules {
-		if err := a.createScheduleFromConfig(ctx, config, groups); err != nil {
-			logger.Error("%s", err)
+	if err := a.client.DeleteSchedule(ctx, scheduleID); err != nil {
+		return bridge.Schedule{}, wrapBridgeError(err)
+	}
+
+	if err := a.syncSchedules(); err != nil {
+		return bridge.Schedule{}, wrapBridgeError(err)
+	}
+
+	return schedule, nil
+}
+
+func (a *app) refreshSchedule(schedule bridge.Schedule) (bridge.Schedule, error) {
+	if err := a.syncSchedules(); err != nil {
+		return bridge.Schedule{}, wrapBridgeError(err)
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if updated, ok := a.schedules[schedule.ID]; ok {
+		return updated, nil
+	}
+
+	return schedule, nil
+}
+
+type scheduleForm struct {
+	Request     scheduleRequest
+	Groups      map[string]Group
+	GroupScenes map[string][]bridge.Scene
+	ID          string
+	States      []string
+}
+
+// DayOptions lists days available for recurring schedules
+func (f scheduleForm) DayOptions() []string {
+	return bridge.RecurrenceDays(bridge.RecurrenceAllDays)
+}
+
+// HasDay checks if the day is selected
+func (f scheduleForm) HasDay(day string) bool {
+	return containsAll(f.Request.Days, []string{day})
+}
+
+// scheduleForms describes the creation form and the edit forms of schedules not managed by configuration file. Caller must hold the read lock
+func (a *app) scheduleForms(groupScenes map[string][]bridge.Scene) (scheduleForm, map[string]scheduleForm) {
+	states := make([]string, 0, len(a.states))
+	for name := range a.states {
+		states = append(states, name)
+	}
+
+	newForm := scheduleForm{
+		Request: scheduleRequest{
+			Kind: bridge.KindRecurring,
+		},
+		Groups:      a.groups,
+		GroupScenes: groupScenes,
+		States:      sortedKeys(states),
+	}
+
+	forms := make(map[string]scheduleForm, len(a.schedules))
+	for id, schedule := range a.schedules {
+		if isManagedSchedule(schedule) || isVacationSchedule(schedule) {
+			continue
+		}
+
+		form := newForm
+		form.ID = id
+		form.Request = newScheduleRequest(schedule)
+		if stateName := findStateName(schedule, nil, a.states); len(form.Request.Scene) == 0 && stateName != "unknown" {
+			form.Request.State = stateName
 		}
+
+		forms[id] = form
 	}
+
+	return newForm, forms
 }
@@ -0,0 +1,99 @@
+package hue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func TestScheduleRequestLocaltime(t *testing.T) {
+	now := time.Date(2021, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		request scheduleRequest
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+		wantErr   bool
+	}{
+		{
+			"recurring",
+			args{
+				request: scheduleRequest{Kind: bridge.KindRecurring, Days: []string{"mon", "fri"}, Time: "07:30"},
+			},
+			"W068/T07:30:00",
+			false,
+		},
+		{
+			"recurring without day",
+			args{
+				request: scheduleRequest{Kind: bridge.KindRecurring, Time: "07:30"},
+			},
+			"",
+			true,
+		},
+		{
+			"absolute",
+			args{
+				request: scheduleRequest{Kind: bridge.KindAbsolute, Time: "2021-12-24T20:00"},
+			},
+			"2021-12-24T20:00:00",
+			false,
+		},
+		{
+			"absolute in the past",
+			args{
+				request: scheduleRequest{Kind: bridge.KindAbsolute, Time: "2021-10-17T11:59:00"},
+			},
+			"",
+			true,
+		},
+		{
+			"timer",
+			args{
+				request: scheduleRequest{Kind: bridge.KindTimer, Time: "00:30:00"},
+			},
+			"PT00:30:00",
+			false,
+		},
+		{
+			"randomized timer",
+			args{
+				request: scheduleRequest{Kind: bridge.KindTimer, Time: "00:30:00", Random: "00:05"},
+			},
+			"PT00:30:00A00:05:00",
+			false,
+		},
+		{
+			"empty timer",
+			args{
+				request: scheduleRequest{Kind: bridge.KindTimer, Time: "00:00"},
+			},
+			"",
+			true,
+		},
+		{
+			"unknown kind",
+			args{
+				request: scheduleRequest{Kind: "sometimes", Time: "07:30"},
+			},
+			"",
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got, err := tc.args.request.localtime(now)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("localtime() error = %v, wantErr %t", err, tc.wantErr)
+			} else if got != tc.want {
+				t.Errorf("localtime() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
@@ -2,32 +2,49 @@ package hue
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
 const (
 	presenceSensorType    = "ZLLPresence"
 	temperatureSensorType = "ZLLTemperature"
+	lightLevelSensorType  = "ZLLLightLevel"
+	tapSensorType         = "ZGPSwitch"
+	switchSensorType      = "ZLLSwitch"
+	statusSensorType      = "CLIPGenericStatus"
 
 	sensorPresenceURL = "/sensors/%s/state/presence"
-)
+	sensorStatusURL   = "/sensors/%s/state/status"
 
-func (a *app) listSensors(ctx context.Context) (map[string]Sensor, error) {
-	var response map[string]Sensor
+	// companionIdle when the room is empty, or lights are switched off by motion
+	companionIdle = 0
+	// companionMotion when lights are switched on by motion
+	companionMotion = 1
+	// companionManual when lights are set by hand, motion doesn't override them until the room is empty
+	companionManual = 2
+)
 
-	if err := get(ctx, fmt.Sprintf("%s/sensors", a.bridgeURL), &response); err != nil {
-		return nil, err
+func (a *app) listSensors(ctx context.Context) (map[string]bridge.Sensor, map[string]string, error) {
+	response, err := a.client.ListSensors(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	sensors := make(map[string]Sensor)
+	sensors := make(map[string]bridge.Sensor)
+	names := make(map[string]string, len(response))
 
 	for id, sensor := range response {
+		names[id] = sensor.Name
+	}
+
+	for _, sensor := range response {
 		if sensor.Type == presenceSensorType {
-			sensor.ID = id
 			sensors[sensor.Name] = sensor
 		}
 	}
@@ -41,29 +58,160 @@ func (a *app) listSensors(ctx context.Context) (map[string]Sensor, error) {
 		}
 	}
 
-	return sensors, nil
+	return sensors, names, nil
 }
 
-func getGroupsActions(groups []string, state string) []Action {
-	actions := make([]Action, 0)
+func getGroupsActions(groups []string, state bridge.State) []bridge.Action {
+	actions := make([]bridge.Action, 0)
 
 	for _, group := range groups {
-		actions = append(actions, Action{
+		actions = append(actions, bridge.Action{
 			Address: fmt.Sprintf("/groups/%s/action", group),
 			Method:  http.MethodPut,
-			Body:    States[state],
+			Body:    state.Body(),
 		})
 	}
 
 	return actions
 }
 
-func (a *app) createSensorOnRuleDescription(sensor configSensor) Rule {
-	state := "on"
+// bands of the sensor, a single one for the whole day if none are configured
+func (s configSensor) bands() []configSensorBand {
+	if len(s.Bands) == 0 {
+		return []configSensorBand{{State: "on"}}
+	}
+
+	return s.Bands
+}
+
+// offState applied when the room is empty
+func (s configSensor) offState() string {
+	if len(s.OffState) == 0 {
+		return "long_off"
+	}
+
+	return s.OffState
+}
+
+func (s configSensor) onRuleName(band configSensorBand) string {
+	return fmt.Sprintf("MotionSensor %s - %s", s.ID, band.name())
+}
+
+func (s configSensor) offRuleName() string {
+	return fmt.Sprintf("MotionSensor %s - %s", s.ID, s.offState())
+}
+
+func (s configSensor) resetRuleName() string {
+	return fmt.Sprintf("MotionSensor %s - reset", s.ID)
+}
+
+func (s configSensor) manualRuleName(groupID string) string {
+	return fmt.Sprintf("MotionSensor %s - manual %s", s.ID, groupID)
+}
+
+// ruleNames lists names of the rules created for the sensor, starting with the off one
+func (s configSensor) ruleNames() []string {
+	names := []string{s.offRuleName()}
+
+	for _, band := range s.bands() {
+		names = append(names, s.onRuleName(band))
+	}
+
+	if len(s.CompanionID) != 0 {
+		names = append(names, s.resetRuleName())
+
+		for _, group := range s.Groups {
+			names = append(names, s.manualRuleName(group))
+		}
+	}
+
+	return names
+}
+
+func (b configSensorBand) name() string {
+	if len(b.Start) == 0 {
+		return b.State
+	}
+
+	return fmt.Sprintf("%s-%s", b.Start, b.End)
+}
+
+// state applied by the band, either a named one or a brightness in percent
+func (b configSensorBand) state(states map[string]bridge.State) bridge.State {
+	if b.Brightness == nil {
+		return states[b.State]
+	}
+
+	return bridge.State{
+		On:             boolPointer(true),
+		Bri:            intPointer(int(math.Max(1, math.Round(float64(*b.Brightness)*254/100)))),
+		TransitionTime: intPointer(30),
+	}
+}
+
+// interval of the band for the `in` operator on `/config/localtime`, wrapping around midnight if end is before start
+func (b configSensorBand) interval() (string, error) {
+	start, err := parseClock(b.Start)
+	if err != nil {
+		return "", fmt.Errorf("malformed start `%s`, e.g. `07:00`", b.Start)
+	}
+
+	end, err := parseClock(b.End)
+	if err != nil {
+		return "", fmt.Errorf("malformed end `%s`, e.g. `22:00`", b.End)
+	}
+
+	if start == end {
+		return "", fmt.Errorf("start and end are both `%s`", b.Start)
+	}
+
+	return fmt.Sprintf("T%s/T%s", formatDuration(start), formatDuration(end)), nil
+}
+
+// statusCondition checks the status of a CLIPGenericStatus sensor
+func statusCondition(sensorID, operator string, status int) bridge.Condition {
+	return bridge.Condition{
+		Address:  fmt.Sprintf(sensorStatusURL, sensorID),
+		Operator: operator,
+		Value:    strconv.Itoa(status),
+	}
+}
+
+// statusAction sets the status of a CLIPGenericStatus sensor
+func statusAction(sensorID string, status int) bridge.Action {
+	return bridge.Action{
+		Address: fmt.Sprintf("/sensors/%s/state", sensorID),
+		Method:  http.MethodPut,
+		Body: map[string]interface{}{
+			"status": status,
+		},
+	}
+}
+
+// companionIDs lists companions of sensors acting on the group
+func companionIDs(sensors []configSensor, groupID string) []string {
+	var output []string
 
-	newRule := Rule{
-		Name: fmt.Sprintf("MotionSensor %s - %s", sensor.ID, state),
-		Conditions: []Condition{
+	for _, sensor := range sensors {
+		if len(sensor.CompanionID) == 0 {
+			continue
+		}
+
+		for _, group := range sensor.Groups {
+			if group == groupID || group == allLightsGroup || groupID == allLightsGroup {
+				output = append(output, sensor.CompanionID)
+				break
+			}
+		}
+	}
+
+	return output
+}
+
+func (a *app) createSensorOnRuleDescription(sensor configSensor, band configSensorBand, states map[string]bridge.State) bridge.Rule {
+	newRule := bridge.Rule{
+		Name: sensor.onRuleName(band),
+		Conditions: []bridge.Condition{
 			{
 				Address:  fmt.Sprintf(sensorPresenceURL, sensor.ID),
 				Operator: "eq",
@@ -73,26 +221,46 @@ func (a *app) createSensorOnRuleDescription(sensor configSensor) Rule {
 				Address:  fmt.Sprintf(sensorPresenceURL, sensor.ID),
 				Operator: "dx",
 			},
-			{
-				Address:  fmt.Sprintf("/sensors/%s/state/daylight", sensor.LightSensorID),
-				Operator: "eq",
-				Value:    "false",
-			},
 		},
-		Actions: make([]Action, 0),
+		Actions: make([]bridge.Action, 0),
 	}
 
-	newRule.Actions = append(newRule.Actions, getGroupsActions(sensor.Groups, state)...)
+	if sensor.LightLevel != nil {
+		newRule.Conditions = append(newRule.Conditions, bridge.Condition{
+			Address:  fmt.Sprintf("/sensors/%s/state/lightlevel", sensor.LightSensorID),
+			Operator: "lt",
+			Value:    strconv.Itoa(*sensor.LightLevel),
+		})
+	} else {
+		newRule.Conditions = append(newRule.Conditions, bridge.Condition{
+			Address:  fmt.Sprintf("/sensors/%s/state/daylight", sensor.LightSensorID),
+			Operator: "eq",
+			Value:    "false",
+		})
+	}
+
+	if interval, err := band.interval(); len(band.Start) != 0 && err == nil {
+		newRule.Conditions = append(newRule.Conditions, bridge.Condition{
+			Address:  "/config/localtime",
+			Operator: "in",
+			Value:    interval,
+		})
+	}
+
+	if len(sensor.CompanionID) != 0 {
+		newRule.Conditions = append(newRule.Conditions, statusCondition(sensor.CompanionID, "lt", companionManual))
+		newRule.Actions = append(newRule.Actions, statusAction(sensor.CompanionID, companionMotion))
+	}
+
+	newRule.Actions = append(newRule.Actions, getGroupsActions(sensor.Groups, band.state(states))...)
 
 	return newRule
 }
 
-func (a *app) createSensorOffRuleDescription(sensor configSensor) Rule {
-	state := "long_off"
-
-	newRule := Rule{
-		Name: fmt.Sprintf("MotionSensor %s - %s", sensor.ID, state),
-		Conditions: []Condition{
+func (a *app) createSensorOffRuleDescription(sensor configSensor, states map[string]bridge.State) bridge.Rule {
+	newRule := bridge.Rule{
+		Name: sensor.offRuleName(),
+		Conditions: []bridge.Condition{
 			{
 				Address:  fmt.Sprintf(sensorPresenceURL, sensor.ID),
 				Operator: "eq",
@@ -104,32 +272,119 @@ func (a *app) createSensorOffRuleDescription(sensor configSensor) Rule {
 				Value:    sensor.OffDelay,
 			},
 		},
-		Actions: make([]Action, 0),
+		Actions: make([]bridge.Action, 0),
 	}
 
-	newRule.Actions = append(newRule.Actions, getGroupsActions(sensor.Groups, state)...)
+	newRule.Actions = append(newRule.Actions, getGroupsActions(sensor.Groups, states[sensor.offState()])...)
+
+	if len(sensor.CompanionID) != 0 {
+		newRule.Conditions = append(newRule.Conditions, statusCondition(sensor.CompanionID, "eq", companionMotion))
+		newRule.Actions = append(newRule.Actions, statusAction(sensor.CompanionID, companionIdle))
+	}
 
 	return newRule
 }
 
-func (a *app) configureMotionSensor(ctx context.Context, sensors []configSensor) {
-	for _, sensor := range sensors {
-		onRule := a.createSensorOnRuleDescription(sensor)
-		if err := a.createRule(ctx, &onRule); err != nil {
-			logger.Error("%s", err)
+// createSensorCompanionRulesDescription tracks lights set by hand in the companion: motion stops overriding them until the room is empty for the off delay
+func (a *app) createSensorCompanionRulesDescription(sensor configSensor) []bridge.Rule {
+	rules := []bridge.Rule{
+		{
+			Name: sensor.resetRuleName(),
+			Conditions: []bridge.Condition{
+				{
+					Address:  fmt.Sprintf(sensorPresenceURL, sensor.ID),
+					Operator: "eq",
+					Value:    "false",
+				},
+				{
+					Address:  fmt.Sprintf(sensorPresenceURL, sensor.ID),
+					Operator: "ddx",
+					Value:    sensor.OffDelay,
+				},
+				statusCondition(sensor.CompanionID, "eq", companionManual),
+			},
+			Actions: []bridge.Action{statusAction(sensor.CompanionID, companionIdle)},
+		},
+	}
+
+	for _, group := range sensor.Groups {
+		rules = append(rules, bridge.Rule{
+			Name: sensor.manualRuleName(group),
+			Conditions: []bridge.Condition{
+				{
+					Address:  fmt.Sprintf("/groups/%s/state/any_on", group),
+					Operator: "eq",
+					Value:    "true",
+				},
+				{
+					Address:  fmt.Sprintf("/groups/%s/state/any_on", group),
+					Operator: "dx",
+				},
+				statusCondition(sensor.CompanionID, "eq", companionIdle),
+			},
+			Actions: []bridge.Action{statusAction(sensor.CompanionID, companionManual)},
+		})
+	}
+
+	return rules
+}
+
+// overrideActions marks lights of the groups as set by hand from a rule, e.g. a switch
+func overrideActions(sensors []configSensor, groupIDs []string) []bridge.Action {
+	var actions []bridge.Action
+	done := make(map[string]bool)
+
+	for _, groupID := range groupIDs {
+		for _, companionID := range companionIDs(sensors, groupID) {
+			if !done[companionID] {
+				done[companionID] = true
+				actions = append(actions, statusAction(companionID, companionManual))
+			}
 		}
+	}
 
-		offRule := a.createSensorOffRuleDescription(sensor)
-		if err := a.createRule(ctx, &offRule); err != nil {
-			logger.Error("%s", err)
+	return actions
+}
+
+// overrideMotion marks lights of the groups as set by hand, for motion sensors not to override them
+func (a *app) overrideMotion(ctx context.Context, groupIDs ...string) {
+	a.mutex.RLock()
+	var sensors []configSensor
+	if a.config != nil {
+		sensors = a.config.Sensors
+	}
+	a.mutex.RUnlock()
+
+	done := make(map[string]bool)
+
+	for _, groupID := range groupIDs {
+		for _, companionID := range companionIDs(sensors, groupID) {
+			if done[companionID] {
+				continue
+			}
+			done[companionID] = true
+
+			if err := a.client.UpdateSensorState(ctx, companionID, map[string]interface{}{"status": companionManual}); err != nil {
+				logger.Warn("unable to mark companion `%s` as overridden: %s", companionID, err)
+			}
 		}
 	}
 }
 
-func (a *app) updateSensorConfig(ctx context.Context, sensor Sensor) error {
-	if sensor.ID == "" {
-		return errors.New("missing sensor ID to update")
+func (a *app) configureMotionSensor(states map[string]bridge.State, sensors []configSensor) []bridge.Rule {
+	var rules []bridge.Rule
+
+	for _, sensor := range sensors {
+		for _, band := range sensor.bands() {
+			rules = append(rules, a.createSensorOnRuleDescription(sensor, band, states))
+		}
+
+		rules = append(rules, a.createSensorOffRuleDescription(sensor, states))
+
+		if len(sensor.CompanionID) != 0 {
+			rules = append(rules, a.createSensorCompanionRulesDescription(sensor)...)
+		}
 	}
 
-	return update(ctx, fmt.Sprintf("%s/sensors/%s/config", a.bridgeURL, sensor.ID), sensor.Config)
+	return rules
 }
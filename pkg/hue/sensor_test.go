@@ -0,0 +1,116 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func TestCreateSensorOnRuleDescription(t *testing.T) {
+	instance := &app{}
+
+	presence := []bridge.Condition{
+		{Address: "/sensors/6/state/presence", Operator: "eq", Value: "true"},
+		{Address: "/sensors/6/state/presence", Operator: "dx"},
+	}
+
+	type args struct {
+		sensor configSensor
+		band   configSensorBand
+	}
+
+	var cases = []struct {
+		intention  string
+		args       args
+		wantName   string
+		want       []bridge.Condition
+		wantAction map[string]interface{}
+		wantCount  int
+	}{
+		{
+			"whole day",
+			args{
+				sensor: configSensor{ID: "6", LightSensorID: "7", Groups: []string{"4"}},
+				band:   configSensorBand{State: "on"},
+			},
+			"MotionSensor 6 - on",
+			append(presence[:2:2], bridge.Condition{Address: "/sensors/7/state/daylight", Operator: "eq", Value: "false"}),
+			States["on"].Body(),
+			1,
+		},
+		{
+			"night band with lightlevel",
+			args{
+				sensor: configSensor{ID: "6", LightSensorID: "7", Groups: []string{"4"}, LightLevel: intPointer(12000)},
+				band:   configSensorBand{Start: "22:00", End: "07:00", Brightness: intPointer(50)},
+			},
+			"MotionSensor 6 - 22:00-07:00",
+			append(presence[:2:2],
+				bridge.Condition{Address: "/sensors/7/state/lightlevel", Operator: "lt", Value: "12000"},
+				bridge.Condition{Address: "/config/localtime", Operator: "in", Value: "T22:00:00/T07:00:00"},
+			),
+			map[string]interface{}{"on": true, "bri": 127, "transitiontime": 30},
+			1,
+		},
+		{
+			"companion",
+			args{
+				sensor: configSensor{ID: "6", LightSensorID: "7", CompanionID: "9", Groups: []string{"4"}},
+				band:   configSensorBand{State: "on"},
+			},
+			"MotionSensor 6 - on",
+			append(presence[:2:2],
+				bridge.Condition{Address: "/sensors/7/state/daylight", Operator: "eq", Value: "false"},
+				bridge.Condition{Address: "/sensors/9/state/status", Operator: "lt", Value: "2"},
+			),
+			States["on"].Body(),
+			2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got := instance.createSensorOnRuleDescription(tc.args.sensor, tc.args.band, States)
+
+			if got.Name != tc.wantName {
+				t.Errorf("createSensorOnRuleDescription().Name = `%s`, want `%s`", got.Name, tc.wantName)
+			}
+
+			if !reflect.DeepEqual(got.Conditions, tc.want) {
+				t.Errorf("createSensorOnRuleDescription().Conditions = %+v, want %+v", got.Conditions, tc.want)
+			}
+
+			if len(got.Actions) != tc.wantCount || !sameJSON(got.Actions[len(got.Actions)-1].Body, tc.wantAction) {
+				t.Errorf("createSensorOnRuleDescription().Actions = %+v, want body %+v", got.Actions, tc.wantAction)
+			}
+		})
+	}
+}
+
+func TestCreateSensorCompanionRulesDescription(t *testing.T) {
+	instance := &app{}
+
+	got := instance.createSensorCompanionRulesDescription(configSensor{ID: "6", CompanionID: "9", OffDelay: "PT00:05:00", Groups: []string{"4"}})
+
+	names := make([]string, len(got))
+	for index, rule := range got {
+		names[index] = rule.Name
+	}
+
+	if want := []string{"MotionSensor 6 - reset", "MotionSensor 6 - manual 4"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("createSensorCompanionRulesDescription() = %#v, want %#v", names, want)
+	}
+
+	// Known limitation: a change made outside of the app while lights are on by motion isn't detected,
+	// rules of the bridge can't tell it from the change made by the motion rule itself
+	want := []bridge.Condition{
+		{Address: "/groups/4/state/any_on", Operator: "eq", Value: "true"},
+		{Address: "/groups/4/state/any_on", Operator: "dx"},
+		{Address: "/sensors/9/state/status", Operator: "eq", Value: "0"},
+	}
+
+	if manual := got[1]; !reflect.DeepEqual(manual.Conditions, want) {
+		t.Errorf("createSensorCompanionRulesDescription() manual = %+v, want %+v", manual.Conditions, want)
+	}
+}
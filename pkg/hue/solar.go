@@ -0,0 +1,203 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/sun"
+)
+
+const (
+	sunrise = "sunrise"
+	sunset  = "sunset"
+)
+
+var solarRegex = regexp.MustCompile(`^(sunrise|sunset)(?:\s*([+-])\s*(\S+))?$`)
+
+type solarTime struct {
+	event  string
+	offset time.Duration
+}
+
+func isSolarTime(localtime string) bool {
+	return strings.HasPrefix(localtime, sunrise) || strings.HasPrefix(localtime, sunset)
+}
+
+// parseSolarTime parses a time relative to the sun, e.g. `sunset - 30m` or `sunrise + 1h15m`
+func parseSolarTime(localtime string) (solarTime, error) {
+	matches := solarRegex.FindStringSubmatch(strings.TrimSpace(localtime))
+	if matches == nil {
+		return solarTime{}, fmt.Errorf("malformed solar time `%s`, e.g. `sunset - 30m`", localtime)
+	}
+
+	output := solarTime{
+		event: matches[1],
+	}
+
+	if len(matches[3]) == 0 {
+		return output, nil
+	}
+
+	offset, err := time.ParseDuration(matches[3])
+	if err != nil {
+		return solarTime{}, fmt.Errorf("malformed offset `%s`, e.g. `30m`: %s", matches[3], err)
+	}
+
+	if offset > 12*time.Hour {
+		return solarTime{}, fmt.Errorf("offset `%s` is greater than 12 hours", matches[3])
+	}
+
+	if matches[2] == "-" {
+		offset = -offset
+	}
+
+	output.offset = offset
+
+	return output, nil
+}
+
+// next computes the first trigger of the solar time after now, skipping days without sunrise or sunset
+func (s solarTime) next(now time.Time, location configLocation) (time.Time, error) {
+	compute := sun.Sunrise
+	if s.event == sunset {
+		compute = sun.Sunset
+	}
+
+	for day := -1; day <= 366; day++ {
+		event, err := compute(now.AddDate(0, 0, day), location.Latitude, location.Longitude)
+		if errors.Is(err, sun.ErrNoEvent) {
+			continue
+		}
+
+		if trigger := event.Add(s.offset); trigger.After(now) {
+			return trigger, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("no %s in the coming year", s.event)
+}
+
+// solarLocaltime converts the solar time to a daily time pattern of the bridge, at its next trigger
+func solarLocaltime(localtime string, now time.Time, location *configLocation) (string, time.Time, error) {
+	if location == nil {
+		return "", time.Time{}, errors.New("location is required for solar times")
+	}
+
+	solar, err := parseSolarTime(localtime)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	next, err := solar.next(now, *location)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	year, month, day := next.Date()
+	pattern := bridge.TimePattern{
+		Kind: bridge.KindRecurring,
+		Days: bridge.RecurrenceAllDays,
+		Time: next.Sub(time.Date(year, month, day, 0, 0, 0, 0, next.Location())),
+	}
+
+	return pattern.Pattern(), next, nil
+}
+
+// resolveSolarSchedules replaces solar times of schedules by their next trigger
+func resolveSolarSchedules(schedules []ScheduleConfig, location *configLocation, now time.Time) ([]ScheduleConfig, error) {
+	output := make([]ScheduleConfig, len(schedules))
+
+	for index, schedule := range schedules {
+		output[index] = schedule
+
+		if !isSolarTime(schedule.Localtime) {
+			continue
+		}
+
+		localtime, _, err := solarLocaltime(schedule.Localtime, now, location)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute time of schedule `%s`: %s", schedule.Name, err)
+		}
+
+		output[index].Localtime = localtime
+	}
+
+	return output, nil
+}
+
+// solarSchedule describes a schedule of the configuration file relative to the sun. Caller must hold the read lock
+func (a *app) solarSchedule(schedule bridge.Schedule, now time.Time) (solarSchedule, bool) {
+	if a.config == nil || !isManagedSchedule(schedule) {
+		return solarSchedule{}, false
+	}
+
+	for _, config := range a.config.Schedules {
+		if config.Name != schedule.Name || !isSolarTime(config.Localtime) {
+			continue
+		}
+
+		output := solarSchedule{
+			Rule: config.Localtime,
+		}
+
+		if _, next, err := solarLocaltime(config.Localtime, now, a.config.Location); err == nil {
+			output.Next = next
+		}
+
+		return output, true
+	}
+
+	return solarSchedule{}, false
+}
+
+// solarSchedules describes schedules relative to the sun, by schedule ID. Caller must hold the read lock
+func (a *app) solarSchedules(now time.Time) map[string]solarSchedule {
+	output := make(map[string]solarSchedule)
+
+	for id, schedule := range a.schedules {
+		if item, ok := a.solarSchedule(schedule, now); ok {
+			output[id] = item
+		}
+	}
+
+	return output
+}
+
+type solarSchedule struct {
+	Next time.Time `json:"next"`
+	Rule string    `json:"rule"`
+}
+
+// reprogramSolarSchedules reconciles the configuration daily, for solar times to follow the sun
+func (a *app) reprogramSolarSchedules(ctx context.Context) error {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
+	a.mutex.RLock()
+	config := a.config
+	a.mutex.RUnlock()
+
+	if config == nil {
+		return nil
+	}
+
+	hasSolar := false
+	for _, schedule := range config.Schedules {
+		hasSolar = hasSolar || isSolarTime(schedule.Localtime)
+	}
+
+	if !hasSolar {
+		return nil
+	}
+
+	if err := a.reconcile(ctx, *config); err != nil {
+		return fmt.Errorf("unable to reprogram solar schedules: %s", err)
+	}
+
+	return a.syncSchedules()
+}
@@ -0,0 +1,114 @@
+package hue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func TestSolarLocaltime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatalf("unable to load location: %s", err)
+	}
+
+	location := &configLocation{Latitude: 48.8566, Longitude: 2.3522}
+
+	type args struct {
+		localtime string
+		now       time.Time
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+		wantNext  string
+	}{
+		{
+			"sunset",
+			args{
+				localtime: "sunset",
+				now:       time.Date(2021, 6, 21, 12, 0, 0, 0, paris),
+			},
+			"W127/T21:57:46",
+			"2021-06-21",
+		},
+		{
+			"before sunset",
+			args{
+				localtime: "sunset - 30m",
+				now:       time.Date(2021, 6, 21, 12, 0, 0, 0, paris),
+			},
+			"W127/T21:27:46",
+			"2021-06-21",
+		},
+		{
+			"after sunrise",
+			args{
+				localtime: "sunrise + 15m",
+				now:       time.Date(2021, 6, 21, 12, 0, 0, 0, paris),
+			},
+			"W127/T06:02:09",
+			"2021-06-22",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got, next, err := solarLocaltime(tc.args.localtime, tc.args.now, location)
+			if err != nil {
+				t.Errorf("solarLocaltime() error = %s", err)
+			} else if got != tc.want || next.Format("2006-01-02") != tc.wantNext {
+				t.Errorf("solarLocaltime() = (`%s`, %s), want (`%s`, %s)", got, next.Format("2006-01-02"), tc.want, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestReconcileSolarTimezone(t *testing.T) {
+	_, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeIP:       fakebridge.Address(server),
+		bridgeUsername: "secret",
+		v1:             true,
+	}
+
+	if err := instance.connect(); err != nil {
+		t.Fatalf("connect() error = %s", err)
+	}
+
+	if got := instance.location.String(); got != "Europe/Paris" {
+		t.Fatalf("connect() location = %s, want Europe/Paris", got)
+	}
+
+	location := &configLocation{Latitude: 48.8566, Longitude: 2.3522}
+	config := configHue{
+		Location:  location,
+		Schedules: []ScheduleConfig{{Name: "Evening", Localtime: "sunset", Group: "1", State: "on"}},
+	}
+
+	if err := instance.reconcile(context.Background(), config); err != nil {
+		t.Fatalf("reconcile() error = %s", err)
+	}
+
+	schedules, err := instance.client.ListSchedules(context.Background())
+	if err != nil {
+		t.Fatalf("ListSchedules() error = %s", err)
+	}
+
+	want, _, err := solarLocaltime("sunset", time.Now().In(instance.location), location)
+	if err != nil {
+		t.Fatalf("solarLocaltime() error = %s", err)
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Name == "Evening" && schedule.Localtime != want {
+			t.Errorf("reconcile() localtime = %s, want %s in bridge timezone", schedule.Localtime, want)
+		}
+	}
+}
@@ -2,14 +2,49 @@ package hue
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/ViBiOh/httputils/v4/pkg/cron"
 	"github.com/ViBiOh/httputils/v4/pkg/logger"
 )
 
+const (
+	connectRetryInterval = time.Second * 30
+)
+
 func (a *app) Start(done <-chan struct{}) {
+	for {
+		err := a.connect()
+		if err == nil {
+			break
+		}
+
+		logger.Error("unable to connect to bridge, retrying in %s: %s", connectRetryInterval, err)
+
+		select {
+		case <-done:
+			return
+		case <-time.After(connectRetryInterval):
+		}
+	}
+
 	a.initConfig()
+	a.initVacation()
+
+	go a.watchConfig(done)
+
+	if !a.clip.IsZero() {
+		go a.streamEvents(done)
+	}
+
+	go cron.New().Days().At("00:05").In(a.location.String()).OnError(func(err error) {
+		logger.Error("%s", err)
+	}).Start(a.reprogramSolarSchedules, done)
+
+	go cron.New().Days().At("00:05").In(a.location.String()).OnError(func(err error) {
+		logger.Error("%s", err)
+	}).Start(a.runVacation, done)
 
 	cron.New().Each(time.Minute).Now().OnError(func(err error) {
 		logger.Error("%s", err)
@@ -22,26 +57,45 @@ func (a *app) initConfig() {
 		return
 	}
 
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
 	logger.Info("Configuring hue...")
 	defer logger.Info("Configuration done.")
 
+	if err := a.reconcile(context.Background(), *a.config); err != nil {
+		logger.Error("%s", err)
+
+		if errors.Is(err, errPartialReconcile) {
+			a.configHash = "" // retry failed changes on next check
+		}
+
+		a.mutex.Lock()
+		a.configErr = err
+		a.mutex.Unlock()
+	}
+}
+
+func (a *app) initVacation() {
 	ctx := context.Background()
 
-	if err := a.cleanSchedules(ctx); err != nil {
+	if err := a.loadVacation(ctx); err != nil {
 		logger.Error("%s", err)
+		return
 	}
 
-	if err := a.cleanRules(ctx); err != nil {
-		logger.Error("%s", err)
+	if a.getVacation() == nil {
+		return
 	}
 
-	if err := a.cleanScenes(ctx); err != nil {
+	if err := a.refreshState(ctx); err != nil {
 		logger.Error("%s", err)
+		return
 	}
 
-	a.configureSchedules(ctx, a.config.Schedules)
-	a.configureTap(ctx, a.config.Taps)
-	a.configureMotionSensor(ctx, a.config.Sensors)
+	if err := a.runVacation(ctx); err != nil {
+		logger.Error("%s", err)
+	}
 }
 
 func (a *app) refreshState(ctx context.Context) error {
@@ -57,35 +111,48 @@ func (a *app) refreshState(ctx context.Context) error {
 		return err
 	}
 
-	scenes, err := a.listScenes(ctx)
+	if err := a.syncScenes(ctx); err != nil {
+		return err
+	}
+
+	go a.updatePrometheusSensors()
+
+	return nil
+}
+
+func (a *app) syncGroups() error {
+	groups, lights, err := a.listGroups(context.Background())
 	if err != nil {
 		return err
 	}
 
 	a.mutex.Lock()
-	a.scenes = scenes
+	a.groups = groups
+	a.lights = lights
 	a.mutex.Unlock()
 
-	go a.updatePrometheusSensors()
+	a.publishChanges()
 
 	return nil
 }
 
-func (a *app) syncGroups() error {
-	groups, err := a.listGroups(context.Background())
+func (a *app) syncScenes(ctx context.Context) error {
+	scenes, err := a.listScenes(ctx)
 	if err != nil {
 		return err
 	}
 
 	a.mutex.Lock()
-	a.groups = groups
+	a.scenes = scenes
 	a.mutex.Unlock()
 
+	a.publishChanges()
+
 	return nil
 }
 
 func (a *app) syncSchedules() error {
-	schedules, err := a.listSchedules(context.Background())
+	schedules, err := a.client.ListSchedules(context.Background())
 	if err != nil {
 		return err
 	}
@@ -94,18 +161,23 @@ func (a *app) syncSchedules() error {
 	a.schedules = schedules
 	a.mutex.Unlock()
 
+	a.publishChanges()
+
 	return nil
 }
 
 func (a *app) syncSensors() error {
-	sensors, err := a.listSensors(context.Background())
+	sensors, names, err := a.listSensors(context.Background())
 	if err != nil {
 		return err
 	}
 
 	a.mutex.Lock()
 	a.sensors = sensors
+	a.sensorNames = names
 	a.mutex.Unlock()
 
+	a.publishChanges()
+
 	return nil
 }
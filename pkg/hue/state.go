@@ -0,0 +1,108 @@
+package hue
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func (s configState) toState() bridge.State {
+	state := bridge.State{
+		On:             boolPointer(true),
+		TransitionTime: intPointer(30),
+		Bri:            s.Bri,
+		Ct:             s.Ct,
+		Hue:            s.Hue,
+		Sat:            s.Sat,
+		Effect:         s.Effect,
+		XY:             s.XY,
+	}
+
+	if s.On != nil {
+		state.On = s.On
+	}
+
+	if s.TransitionTime != nil {
+		state.TransitionTime = s.TransitionTime
+	}
+
+	return state
+}
+
+func (s configState) validate(path string) []error {
+	var errs []error
+
+	checkRange := func(name string, value *int, min, max int) {
+		if value != nil && (*value < min || *value > max) {
+			errs = append(errs, newConfigError(joinPath(path, name), "%d is out of range, must be between %d and %d", *value, min, max))
+		}
+	}
+
+	checkRange("bri", s.Bri, 1, 254)
+	checkRange("ct", s.Ct, 153, 500)
+	checkRange("hue", s.Hue, 0, 65535)
+	checkRange("sat", s.Sat, 0, 254)
+	checkRange("transitiontime", s.TransitionTime, 0, 65535)
+
+	if s.XY != nil {
+		if len(s.XY) != 2 {
+			errs = append(errs, newConfigError(joinPath(path, "xy"), "must contain exactly two coordinates"))
+		} else {
+			for index, value := range s.XY {
+				if value < 0 || value > 1 {
+					errs = append(errs, newConfigError(joinPath(path, fmt.Sprintf("xy[%d]", index)), "%g is out of range, must be between 0 and 1", value))
+				}
+			}
+		}
+	}
+
+	if len(s.Effect) != 0 && s.Effect != "none" && s.Effect != "colorloop" {
+		errs = append(errs, newConfigError(joinPath(path, "effect"), "unknown effect `%s`, must be none or colorloop", s.Effect))
+	}
+
+	colorModes := 0
+	if s.Ct != nil {
+		colorModes++
+	}
+	if s.Hue != nil || s.Sat != nil {
+		colorModes++
+	}
+	if s.XY != nil {
+		colorModes++
+	}
+
+	if colorModes > 1 {
+		errs = append(errs, newConfigError(path, "only one of ct, hue/sat or xy can be set"))
+	}
+
+	return errs
+}
+
+func (c configHue) lightStates() map[string]bridge.State {
+	states := make(map[string]bridge.State, len(States)+len(c.States))
+
+	for name, state := range States {
+		states[name] = state
+	}
+
+	for name, state := range c.States {
+		states[name] = state.toState()
+	}
+
+	return states
+}
+
+func customStateNames(states map[string]bridge.State) []string {
+	var names []string
+
+	for name := range states {
+		if _, ok := States[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,206 @@
+package hue
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+)
+
+const (
+	streamKeepAlive    = time.Second * 15
+	streamWriteTimeout = time.Second * 10
+	streamRetry        = time.Second
+	streamBufferSize   = 64
+)
+
+type groupUpdate struct {
+	ID    string `json:"id"`
+	Scene string `json:"scene"`
+	On    bool   `json:"on"`
+}
+
+type lightUpdate struct {
+	ID         string `json:"id"`
+	Brightness int    `json:"brightness"`
+	On         bool   `json:"on"`
+}
+
+type sensorUpdate struct {
+	ID              string  `json:"id"`
+	TemperatureIcon string  `json:"temperatureIcon"`
+	Temperature     float32 `json:"temperature"`
+	Presence        bool    `json:"presence"`
+}
+
+type scheduleUpdate struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func formatStreamEvent(name string, payload interface{}) string {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("unable to marshal %s event: %s", name, err)
+		return ""
+	}
+
+	return fmt.Sprintf("event: %s\ndata: %s\n\n", name, content)
+}
+
+// streamSnapshot formats current state as events, by item. Caller must hold the read lock
+func (a *app) streamSnapshot() map[string]string {
+	snapshot := make(map[string]string, len(a.groups)+len(a.lights)+len(a.sensors)+len(a.schedules))
+
+	for id, group := range a.groups {
+		snapshot["group/"+id] = formatStreamEvent("group", groupUpdate{ID: id, On: group.State.AnyOn, Scene: a.activeScene(id)})
+	}
+
+	for id, light := range a.lights {
+		snapshot["light/"+id] = formatStreamEvent("light", lightUpdate{ID: id, On: light.State.On, Brightness: brightnessPercent(light.State.Bri)})
+	}
+
+	for _, sensor := range a.sensors {
+		snapshot["sensor/"+sensor.ID] = formatStreamEvent("sensor", sensorUpdate{
+			ID:              sensor.ID,
+			Presence:        sensor.State.Presence,
+			Temperature:     sensor.State.Temperature,
+			TemperatureIcon: temperatureIcon(sensor.State.Temperature),
+		})
+	}
+
+	for id, schedule := range a.schedules {
+		snapshot["schedule/"+id] = formatStreamEvent("schedule", scheduleUpdate{ID: id, Status: schedule.Status})
+	}
+
+	return snapshot
+}
+
+// publishChanges sends to listeners the items that changed since last call
+func (a *app) publishChanges() {
+	a.mutex.RLock()
+	snapshot := a.streamSnapshot()
+	a.mutex.RUnlock()
+
+	a.streamMutex.Lock()
+	defer a.streamMutex.Unlock()
+
+	keys := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if a.streamState[key] == snapshot[key] {
+			continue
+		}
+
+		for listener := range a.streamListeners {
+			select {
+			case listener <- snapshot[key]:
+			default:
+				logger.Warn("events listener is too slow, dropping event")
+			}
+		}
+	}
+
+	a.streamState = snapshot
+}
+
+// handleEvents serves the stream on the hijacked connection, so each write is bounded instead of the whole response by the server's write timeout
+func (a *app) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	listener := make(chan string, streamBufferSize)
+
+	a.streamMutex.Lock()
+	if a.streamListeners == nil {
+		a.streamListeners = make(map[chan string]struct{})
+	}
+	a.streamListeners[listener] = struct{}{}
+	a.streamMutex.Unlock()
+
+	defer func() {
+		a.streamMutex.Lock()
+		delete(a.streamListeners, listener)
+		a.streamMutex.Unlock()
+	}()
+
+	// snapshot is taken once registered, a change published in between is sent twice rather than lost
+	a.mutex.RLock()
+	snapshot := a.streamSnapshot()
+	a.mutex.RUnlock()
+
+	conn, stream, err := hijacker.Hijack()
+	if err != nil {
+		logger.Error("unable to hijack events connection: %s", err)
+		return
+	}
+
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logger.Error("unable to close events connection: %s", err)
+		}
+	}()
+
+	send := func(content string) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			return err
+		}
+
+		if _, err := stream.WriteString(content); err != nil {
+			return err
+		}
+
+		return stream.Flush()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "close")
+
+	var content strings.Builder
+	content.WriteString("HTTP/1.1 200 OK\r\n")
+	_ = w.Header().Write(&content)
+	content.WriteString("\r\n")
+
+	fmt.Fprintf(&content, "retry: %d\n\n", streamRetry.Milliseconds())
+	for _, message := range snapshot {
+		content.WriteString(message)
+	}
+
+	if err := send(content.String()); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		var message string
+
+		select {
+		case <-keepAlive.C:
+			message = ": keep-alive\n\n"
+		case message = <-listener:
+		}
+
+		if err := send(message); err != nil {
+			return
+		}
+	}
+}
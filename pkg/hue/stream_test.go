@@ -0,0 +1,205 @@
+package hue
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func TestPublishChanges(t *testing.T) {
+	type args struct {
+		update func(*app)
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      []string
+	}{
+		{
+			"no change",
+			args{
+				update: func(*app) {},
+			},
+			nil,
+		},
+		{
+			"group and schedule",
+			args{
+				update: func(a *app) {
+					a.groups["1"] = Group{Group: bridge.Group{State: bridge.GroupState{AnyOn: true}}}
+					a.schedules["3"] = bridge.Schedule{ID: "3", APISchedule: bridge.APISchedule{Status: "disabled"}}
+				},
+			},
+			[]string{
+				"event: group\ndata: {\"id\":\"1\",\"scene\":\"\",\"on\":true}\n\n",
+				"event: schedule\ndata: {\"id\":\"3\",\"status\":\"disabled\"}\n\n",
+			},
+		},
+		{
+			"sensor",
+			args{
+				update: func(a *app) {
+					a.sensors["Hallway"] = bridge.Sensor{ID: "5", State: bridge.SensorState{Presence: true, Temperature: 29}}
+				},
+			},
+			[]string{
+				"event: sensor\ndata: {\"id\":\"5\",\"temperatureIcon\":\"thermometer-full?fill=salmon\",\"temperature\":29,\"presence\":true}\n\n",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			instance := &app{
+				groups:    map[string]Group{"1": {}},
+				sensors:   map[string]bridge.Sensor{"Hallway": {ID: "5", State: bridge.SensorState{Temperature: 20}}},
+				schedules: map[string]bridge.Schedule{"3": {ID: "3", APISchedule: bridge.APISchedule{Status: "enabled"}}},
+			}
+			instance.publishChanges()
+
+			listener := make(chan string, streamBufferSize)
+			instance.streamListeners = map[chan string]struct{}{listener: {}}
+
+			tc.args.update(instance)
+			instance.publishChanges()
+			close(listener)
+
+			var got []string
+			for message := range listener {
+				got = append(got, message)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("publishChanges() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleEvents(t *testing.T) {
+	_, bridgeServer := fakebridge.NewServer("secret")
+	defer bridgeServer.Close()
+
+	instance := &app{
+		bridgeUsername: "secret",
+		client:         bridge.New(fakebridge.Address(bridgeServer), "secret"),
+		groups:         map[string]Group{"1": {}},
+	}
+	instance.publishChanges()
+
+	server := httptest.NewUnstartedServer(instance.Handler())
+	server.Config.WriteTimeout = time.Millisecond * 100
+	server.Start()
+	defer server.Close()
+
+	response, err := http.Get(server.URL + eventsPath)
+	if err != nil {
+		t.Fatalf("Get() error = %s", err)
+	}
+	defer response.Body.Close()
+
+	if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("handleEvents() Content-Type = `%s`, want `text/event-stream`", got)
+	}
+
+	reader := bufio.NewReader(response.Body)
+	readEvent := func() string {
+		var event strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("ReadString() error = %s", err)
+			}
+
+			if line == "\n" {
+				return event.String()
+			}
+
+			event.WriteString(line)
+		}
+	}
+
+	if got := readEvent(); got != "retry: 1000\n" {
+		t.Errorf("handleEvents() = `%s`, want retry", got)
+	}
+
+	if got, want := readEvent(), "event: group\ndata: {\"id\":\"1\",\"scene\":\"\",\"on\":false}\n"; got != want {
+		t.Errorf("handleEvents() = `%s`, want `%s`", got, want)
+	}
+
+	time.Sleep(server.Config.WriteTimeout * 2)
+
+	instance.mutex.Lock()
+	instance.groups["1"] = Group{Group: bridge.Group{State: bridge.GroupState{AnyOn: true}}}
+	instance.mutex.Unlock()
+	instance.publishChanges()
+
+	if got, want := readEvent(), "event: group\ndata: {\"id\":\"1\",\"scene\":\"\",\"on\":true}\n"; got != want {
+		t.Errorf("handleEvents() after write timeout = `%s`, want `%s`", got, want)
+	}
+}
+
+func TestHandleEventsRegisterBeforeSnapshot(t *testing.T) {
+	instance := &app{
+		groups: map[string]Group{"1": {}},
+	}
+	instance.publishChanges()
+
+	server := httptest.NewServer(http.HandlerFunc(instance.handleEvents))
+	defer server.Close()
+
+	// holding the lock blocks the snapshot, the change is made once the listener is registered
+	instance.mutex.Lock()
+
+	registered := make(chan bool, 1)
+
+	go func() {
+		found := false
+
+		for deadline := time.Now().Add(time.Second); !found && time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			instance.streamMutex.Lock()
+			found = len(instance.streamListeners) != 0
+			instance.streamMutex.Unlock()
+		}
+
+		registered <- found
+
+		instance.groups["1"] = Group{Group: bridge.Group{State: bridge.GroupState{AnyOn: true}}}
+		instance.mutex.Unlock()
+		instance.publishChanges()
+	}()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %s", err)
+	}
+	defer response.Body.Close()
+
+	if !<-registered {
+		t.Error("handleEvents() took snapshot before registering listener")
+	}
+
+	want := "data: {\"id\":\"1\",\"scene\":\"\",\"on\":true}\n"
+	reader := bufio.NewReader(response.Body)
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString() error = %s", err)
+		}
+
+		if line == want {
+			return
+		}
+	}
+
+	t.Errorf("handleEvents() didn't send `%s`", want)
+}
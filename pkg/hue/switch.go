@@ -0,0 +1,127 @@
+package hue
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	smartButtonModel = "ROM"
+)
+
+var (
+	switchButtonMapping = map[string]int{
+		"1":    1,
+		"2":    2,
+		"3":    3,
+		"4":    4,
+		"on":   1,
+		"up":   2,
+		"down": 3,
+		"off":  4,
+	}
+
+	switchEventMapping = map[string]int{
+		"initial_press": 0,
+		"hold":          1,
+		"short_release": 2,
+		"long_release":  3,
+	}
+)
+
+// buttonEvent computes the code of the event for Dimmer Switches and Smart Buttons, e.g. 2001 when `up` is held
+func (b configSwitchButton) buttonEvent() int {
+	return switchButtonMapping[b.Button]*1000 + switchEventMapping[b.Event]
+}
+
+// stepState is the state applied by a brightness step, in percent
+func (b configSwitchButton) stepState() bridge.State {
+	return bridge.State{
+		BriInc:         intPointer(int(math.Round(float64(*b.BrightnessStep) * 254 / 100))),
+		TransitionTime: intPointer(4),
+	}
+}
+
+func switchConditions(switchID string, event int) []bridge.Condition {
+	return []bridge.Condition{
+		{
+			Address:  fmt.Sprintf("/sensors/%s/state/buttonevent", switchID),
+			Operator: "eq",
+			Value:    strconv.Itoa(event),
+		},
+		{
+			Address:  fmt.Sprintf("/sensors/%s/state/lastupdated", switchID),
+			Operator: "dx",
+		},
+	}
+}
+
+// cycleRules applies states in turn on each trigger, the position being stored in the status of a CLIPGenericStatus counter
+func cycleRules(name string, conditions []bridge.Condition, counterID string, actions [][]bridge.Action) []bridge.Rule {
+	rules := make([]bridge.Rule, len(actions))
+
+	for index, stateActions := range actions {
+		counter := statusCondition(counterID, "eq", index)
+		if index == len(actions)-1 {
+			// last one also catches unknown values of the counter, for going back to the first one
+			counter = statusCondition(counterID, "gt", index-1)
+		}
+
+		rules[index] = bridge.Rule{
+			Name:       fmt.Sprintf("%s #%d", name, index+1),
+			Conditions: append(append([]bridge.Condition{}, conditions...), counter),
+			Actions:    append(append([]bridge.Action{}, stateActions...), statusAction(counterID, (index+1)%len(actions))),
+		}
+	}
+
+	return rules
+}
+
+func (a *app) createSwitchRulesDescription(switchID string, button configSwitchButton, states map[string]bridge.State, sensors []configSensor) []bridge.Rule {
+	name := fmt.Sprintf("Switch %s %s %s", switchID, button.Button, button.Event)
+	conditions := switchConditions(switchID, button.buttonEvent())
+	override := overrideActions(sensors, button.Groups)
+
+	if button.BrightnessStep != nil {
+		return []bridge.Rule{{
+			Name:       name,
+			Conditions: conditions,
+			Actions:    append(getGroupsActions(button.Groups, button.stepState()), override...),
+		}}
+	}
+
+	if len(button.States) == 1 {
+		return []bridge.Rule{{
+			Name:       name,
+			Conditions: conditions,
+			Actions:    append(getGroupsActions(button.Groups, states[button.States[0]]), override...),
+		}}
+	}
+
+	actions := make([][]bridge.Action, len(button.States))
+	for index, state := range button.States {
+		actions[index] = append(getGroupsActions(button.Groups, states[state]), override...)
+	}
+
+	return cycleRules(name, conditions, button.CounterID, actions)
+}
+
+func (a *app) configureSwitches(states map[string]bridge.State, switches []configSwitch, sensors []configSensor) []bridge.Rule {
+	var rules []bridge.Rule
+
+	for _, item := range switches {
+		for _, button := range item.Buttons {
+			rules = append(rules, a.createSwitchRulesDescription(item.ID, button, states, sensors)...)
+		}
+	}
+
+	return rules
+}
+
+func isSmartButton(sensor bridge.Sensor) bool {
+	return strings.HasPrefix(sensor.ModelID, smartButtonModel)
+}
@@ -0,0 +1,64 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateSwitchRulesDescription(t *testing.T) {
+	instance := &app{}
+
+	type args struct {
+		button configSwitchButton
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      []string
+	}{
+		{
+			"single state",
+			args{
+				button: configSwitchButton{Button: "off", Event: "short_release", States: []string{"off"}, Groups: []string{"1"}},
+			},
+			[]string{
+				`Switch 10 off short_release: [{"address":"/sensors/10/state/buttonevent","operator":"eq","value":"4002"},{"address":"/sensors/10/state/lastupdated","operator":"dx"}] => [{"address":"/groups/1/action","body":{"on":false,"transitiontime":30},"method":"PUT"}]`,
+			},
+		},
+		{
+			"brightness while held",
+			args{
+				button: configSwitchButton{Button: "up", Event: "hold", BrightnessStep: intPointer(10), Groups: []string{"1"}},
+			},
+			[]string{
+				`Switch 10 up hold: [{"address":"/sensors/10/state/buttonevent","operator":"eq","value":"2001"},{"address":"/sensors/10/state/lastupdated","operator":"dx"}] => [{"address":"/groups/1/action","body":{"bri_inc":25,"transitiontime":4},"method":"PUT"}]`,
+			},
+		},
+		{
+			"cycle",
+			args{
+				button: configSwitchButton{Button: "on", Event: "initial_press", States: []string{"on", "off"}, CounterID: "9", Groups: []string{"1"}},
+			},
+			[]string{
+				`Switch 10 on initial_press #1: [{"address":"/sensors/10/state/buttonevent","operator":"eq","value":"1000"},{"address":"/sensors/10/state/lastupdated","operator":"dx"},{"address":"/sensors/9/state/status","operator":"eq","value":"0"}] => [{"address":"/groups/1/action","body":{"bri":255,"on":true,"sat":0,"transitiontime":30},"method":"PUT"},{"address":"/sensors/9/state","body":{"status":1},"method":"PUT"}]`,
+				`Switch 10 on initial_press #2: [{"address":"/sensors/10/state/buttonevent","operator":"eq","value":"1000"},{"address":"/sensors/10/state/lastupdated","operator":"dx"},{"address":"/sensors/9/state/status","operator":"gt","value":"0"}] => [{"address":"/groups/1/action","body":{"on":false,"transitiontime":30},"method":"PUT"},{"address":"/sensors/9/state","body":{"status":0},"method":"PUT"}]`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			rules := instance.createSwitchRulesDescription("10", tc.args.button, States, nil)
+
+			got := make([]string, len(rules))
+			for index, rule := range rules {
+				got[index] = rule.Name + ": " + canonicalJSON(rule.Conditions) + " => " + canonicalJSON(rule.Actions)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("createSwitchRulesDescription() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,10 @@
 package hue
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 
-	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/hue/pkg/bridge"
 )
 
 var (
@@ -17,41 +16,127 @@ var (
 	}
 )
 
-func (a *app) createRuleDescription(tapID string, button configTapButton) Rule {
-	newRule := Rule{
-		Name: fmt.Sprintf("Tap %s.%s", tapID, button.ID),
-		Conditions: []Condition{
+// toggleState is the state applied when toggling lights on, `on` by default
+func (b configTapButton) toggleState() string {
+	if len(b.State) != 0 {
+		return b.State
+	}
+
+	return "on"
+}
+
+// findScene finds the scene of the bridge with given name applying to lights of the group
+func findScene(state bridgeState, groupID, name string) (string, bool) {
+	group, ok := state.groups[groupID]
+
+	for _, sceneID := range state.sceneIDs() {
+		scene := state.scenes[sceneID]
+		if scene.Name != name || isManagedScene(scene) || len(scene.Lights) == 0 {
+			continue
+		}
+
+		if scene.Type == "GroupScene" {
+			if scene.Group == groupID {
+				return sceneID, true
+			}
+
+			continue
+		}
+
+		if ok && containsAll(group.Lights, scene.Lights) {
+			return sceneID, true
+		}
+	}
+
+	return "", false
+}
+
+func getScenesActions(groups []string, name string, state bridgeState) []bridge.Action {
+	actions := make([]bridge.Action, 0)
+
+	for _, group := range groups {
+		sceneID, _ := findScene(state, group, name)
+
+		actions = append(actions, bridge.Action{
+			Address: fmt.Sprintf("/groups/%s/action", group),
+			Method:  http.MethodPut,
+			Body:    map[string]interface{}{"scene": sceneID},
+		})
+	}
+
+	return actions
+}
+
+func (a *app) createRuleDescription(tapID string, button configTapButton, states map[string]bridge.State, state bridgeState, sensors []configSensor) []bridge.Rule {
+	name := fmt.Sprintf("Tap %s.%s", tapID, button.ID)
+	conditions := []bridge.Condition{
+		{
+			Address:  fmt.Sprintf("/sensors/%s/state/buttonevent", tapID),
+			Operator: "dx",
+		},
+		{
+			Address:  fmt.Sprintf("/sensors/%s/state/buttonevent", tapID),
+			Operator: "eq",
+			Value:    tapButtonMapping[button.ID],
+		},
+	}
+	override := overrideActions(sensors, button.Groups)
+
+	if button.Toggle {
+		allOn := fmt.Sprintf("/groups/%s/state/all_on", button.Groups[0])
+
+		return []bridge.Rule{
 			{
-				Address:  fmt.Sprintf("/sensors/%s/state/buttonevent", tapID),
-				Operator: "dx",
+				Name:       name + " on",
+				Conditions: append(append([]bridge.Condition{}, conditions...), bridge.Condition{Address: allOn, Operator: "eq", Value: "false"}),
+				Actions:    append(getGroupsActions(button.Groups, states[button.toggleState()]), override...),
 			},
 			{
-				Address:  fmt.Sprintf("/sensors/%s/state/buttonevent", tapID),
-				Operator: "eq",
-				Value:    tapButtonMapping[button.ID],
+				Name:       name + " off",
+				Conditions: append(append([]bridge.Condition{}, conditions...), bridge.Condition{Address: allOn, Operator: "eq", Value: "true"}),
+				Actions:    append(getGroupsActions(button.Groups, states["off"]), override...),
 			},
-		},
-		Actions: make([]Action, 0),
+		}
 	}
 
-	for _, group := range button.Groups {
-		newRule.Actions = append(newRule.Actions, Action{
-			Address: fmt.Sprintf("/groups/%s/action", group),
-			Method:  http.MethodPut,
-			Body:    States[button.State],
-		})
+	var actions [][]bridge.Action
+
+	switch {
+	case len(button.State) != 0:
+		actions = append(actions, getGroupsActions(button.Groups, states[button.State]))
+	case len(button.Scenes) != 0:
+		for _, scene := range button.Scenes {
+			actions = append(actions, getScenesActions(button.Groups, scene, state))
+		}
+	default:
+		for _, stateName := range button.States {
+			actions = append(actions, getGroupsActions(button.Groups, states[stateName]))
+		}
+	}
+
+	for index := range actions {
+		actions[index] = append(actions[index], override...)
 	}
 
-	return newRule
+	if len(actions) == 1 {
+		return []bridge.Rule{{
+			Name:       name,
+			Conditions: conditions,
+			Actions:    actions[0],
+		}}
+	}
+
+	return cycleRules(name, conditions, button.CounterID, actions)
 }
 
-func (a *app) configureTap(ctx context.Context, taps []configTap) {
+func (a *app) configureTap(state bridgeState, states map[string]bridge.State, taps []configTap, sensors []configSensor) []bridge.Rule {
+	var rules []bridge.Rule
+
 	for _, tap := range taps {
 		for _, button := range tap.Buttons {
-			button.Rule = a.createRuleDescription(tap.ID, button)
-			if err := a.createRule(ctx, &button.Rule); err != nil {
-				logger.Error("%s", err)
-			}
+			rules = append(rules, a.createRuleDescription(tap.ID, button, states, state, sensors)...)
 		}
 	}
+
+	return rules
 }
@@ -0,0 +1,67 @@
+package hue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+func TestCreateRuleDescription(t *testing.T) {
+	instance := &app{}
+
+	state := bridgeState{
+		groups: map[string]Group{
+			"1": {Group: bridge.Group{Lights: []string{"1", "2"}}},
+		},
+		scenes: map[string]bridge.Scene{
+			"abc": {ID: "abc", APIScene: bridge.APIScene{Name: "Relax", Type: "GroupScene", Group: "1", Lights: []string{"1", "2"}}},
+			"def": {ID: "def", APIScene: bridge.APIScene{Name: "Read", Lights: []string{"2"}}},
+		},
+	}
+
+	var cases = []struct {
+		intention string
+		args      configTapButton
+		want      []string
+	}{
+		{
+			"single state",
+			configTapButton{ID: "1", State: "off", Groups: []string{"1"}},
+			[]string{
+				`Tap 8.1: [{"address":"/sensors/8/state/buttonevent","operator":"dx"},{"address":"/sensors/8/state/buttonevent","operator":"eq","value":"34"}] => [{"address":"/groups/1/action","body":{"on":false,"transitiontime":30},"method":"PUT"}]`,
+			},
+		},
+		{
+			"toggle",
+			configTapButton{ID: "2", Toggle: true, Groups: []string{"1"}},
+			[]string{
+				`Tap 8.2 on: [{"address":"/sensors/8/state/buttonevent","operator":"dx"},{"address":"/sensors/8/state/buttonevent","operator":"eq","value":"16"},{"address":"/groups/1/state/all_on","operator":"eq","value":"false"}] => [{"address":"/groups/1/action","body":{"bri":255,"on":true,"sat":0,"transitiontime":30},"method":"PUT"}]`,
+				`Tap 8.2 off: [{"address":"/sensors/8/state/buttonevent","operator":"dx"},{"address":"/sensors/8/state/buttonevent","operator":"eq","value":"16"},{"address":"/groups/1/state/all_on","operator":"eq","value":"true"}] => [{"address":"/groups/1/action","body":{"on":false,"transitiontime":30},"method":"PUT"}]`,
+			},
+		},
+		{
+			"cycle scenes",
+			configTapButton{ID: "3", Scenes: []string{"Relax", "Read"}, CounterID: "9", Groups: []string{"1"}},
+			[]string{
+				`Tap 8.3 #1: [{"address":"/sensors/8/state/buttonevent","operator":"dx"},{"address":"/sensors/8/state/buttonevent","operator":"eq","value":"17"},{"address":"/sensors/9/state/status","operator":"eq","value":"0"}] => [{"address":"/groups/1/action","body":{"scene":"abc"},"method":"PUT"},{"address":"/sensors/9/state","body":{"status":1},"method":"PUT"}]`,
+				`Tap 8.3 #2: [{"address":"/sensors/8/state/buttonevent","operator":"dx"},{"address":"/sensors/8/state/buttonevent","operator":"eq","value":"17"},{"address":"/sensors/9/state/status","operator":"gt","value":"0"}] => [{"address":"/groups/1/action","body":{"scene":"def"},"method":"PUT"},{"address":"/sensors/9/state","body":{"status":0},"method":"PUT"}]`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			rules := instance.createRuleDescription("8", tc.args, States, state, nil)
+
+			got := make([]string, len(rules))
+			for index, rule := range rules {
+				got[index] = rule.Name + ": " + canonicalJSON(rule.Conditions) + " => " + canonicalJSON(rule.Actions)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("createRuleDescription() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
@@ -19,29 +19,34 @@ var (
 				return "battery-empty?fill=salmon"
 			}
 		},
-		"temperature": func(value float32) string {
-			switch {
-			case value >= 28:
-				return "thermometer-full?fill=salmon"
-			case value >= 24:
-				return "thermometer-three-quarters?fill=darkorange"
-			case value >= 18:
-				return "thermometer-half?fill=limegreen"
-			case value >= 14:
-				return "thermometer-half?fill=darkorange"
-			case value >= 10:
-				return "thermometer-quarter?fill=darkorange"
-			case value >= 4:
-				return "thermometer-empty?fill=salmon"
-			default:
-				return "snowflake?fill=cornflowerblue"
-			}
-		},
+		"temperature": temperatureIcon,
+		"brightness":  brightnessPercent,
+		"kelvin":      mirekToKelvin,
 		"groupName": func(groups map[string]Group, id string) string {
 			if group, ok := groups[id]; ok {
 				return group.Name
 			}
 			return ""
 		},
+		"stateName": findStateName,
 	}
 )
+
+func temperatureIcon(value float32) string {
+	switch {
+	case value >= 28:
+		return "thermometer-full?fill=salmon"
+	case value >= 24:
+		return "thermometer-three-quarters?fill=darkorange"
+	case value >= 18:
+		return "thermometer-half?fill=limegreen"
+	case value >= 14:
+		return "thermometer-half?fill=darkorange"
+	case value >= 10:
+		return "thermometer-quarter?fill=darkorange"
+	case value >= 4:
+		return "thermometer-empty?fill=salmon"
+	default:
+		return "snowflake?fill=cornflowerblue"
+	}
+}
@@ -0,0 +1,554 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/httputils/v4/pkg/logger"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/hue/pkg/bridge"
+)
+
+const (
+	vacationTag      = "hue.vacation"
+	vacationName     = "Vacation"
+	vacationOngoing  = "Vacation ongoing"
+	vacationClassID  = 1
+	vacationMaxLinks = 64
+
+	dayFormat       = "2006-01-02"
+	minimumLighting = 15 * time.Minute
+)
+
+var defaultVacationWindows = []vacationWindow{{Start: "18:30", End: "23:30"}}
+
+type vacationWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func (w vacationWindow) String() string {
+	return fmt.Sprintf("%s-%s", w.Start, w.End)
+}
+
+func (w vacationWindow) bounds() (time.Duration, time.Duration, error) {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window start `%s`, expected HH:MM", w.Start)
+	}
+
+	end, err := parseClock(w.End)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window end `%s`, expected HH:MM", w.End)
+	}
+
+	if end-start < 2*minimumLighting {
+		return 0, 0, fmt.Errorf("window `%s` must last at least %s", w, 2*minimumLighting)
+	}
+
+	return start, end, nil
+}
+
+type vacationRequest struct {
+	Start   string           `json:"start"`
+	End     string           `json:"end"`
+	State   string           `json:"state,omitempty"`
+	Groups  []string         `json:"groups"`
+	Windows []vacationWindow `json:"windows,omitempty"`
+}
+
+// vacation simulates presence between its start day and its end day, the day of return
+type vacation struct {
+	Start     time.Time
+	End       time.Time
+	ID        string
+	State     string
+	Groups    []string
+	Windows   []vacationWindow
+	Schedules []string
+	Rules     []string
+	Ongoing   bool
+}
+
+type vacationSwitch struct {
+	State string
+	Date  time.Time
+}
+
+// parseVacationRequest checks the request against known groups and states
+func parseVacationRequest(request vacationRequest, now time.Time, groups map[string]Group, states map[string]bridge.State) (vacation, error) {
+	var errs []error
+
+	output := vacation{
+		State:   request.State,
+		Groups:  request.Groups,
+		Windows: request.Windows,
+	}
+
+	today := midnight(now)
+
+	start, err := time.ParseInLocation(dayFormat, request.Start, now.Location())
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid start `%s`, expected YYYY-MM-DD", request.Start))
+	} else if start.Before(today) {
+		errs = append(errs, fmt.Errorf("start `%s` is in the past", request.Start))
+	}
+
+	end, err := time.ParseInLocation(dayFormat, request.End, now.Location())
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid end `%s`, expected YYYY-MM-DD", request.End))
+	} else if !end.After(start) {
+		errs = append(errs, fmt.Errorf("end `%s` must be after start", request.End))
+	}
+
+	output.Start = start
+	output.End = end
+
+	if len(output.State) == 0 {
+		output.State = "half"
+	}
+
+	if _, ok := states[output.State]; !ok || output.State == "off" {
+		errs = append(errs, fmt.Errorf("unknown state '%s' for switching on", output.State))
+	}
+
+	if len(output.Groups) == 0 {
+		errs = append(errs, errors.New("at least one group is required"))
+	}
+
+	for _, groupID := range output.Groups {
+		if _, ok := groups[groupID]; !ok {
+			errs = append(errs, fmt.Errorf("unknown group '%s'", groupID))
+		}
+	}
+
+	if len(output.Windows) == 0 {
+		output.Windows = defaultVacationWindows
+	}
+
+	for _, window := range output.Windows {
+		if _, _, err := window.bounds(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if description := output.description(); len(description) > 64 {
+		errs = append(errs, fmt.Errorf("too many windows to be stored on bridge: `%s`", description))
+	}
+
+	for _, groupID := range output.Groups {
+		if name := vacationScheduleName(groupID, start, len(output.Windows)-1, false); len(name) > maxNameLength {
+			errs = append(errs, fmt.Errorf("group '%s' gives schedule names longer than %d characters, e.g. `%s`", groupID, maxNameLength, name))
+		}
+	}
+
+	if len(errs) != 0 {
+		return vacation{}, joinInvalid(errs)
+	}
+
+	return output, nil
+}
+
+// description encodes the period, the windows and the state in the 64 characters of a resource link
+func (v vacation) description() string {
+	windows := make([]string, len(v.Windows))
+	for index, window := range v.Windows {
+		windows[index] = window.String()
+	}
+
+	return fmt.Sprintf("%s/%s %s %s", v.Start.Format(dayFormat), v.End.Format(dayFormat), strings.Join(windows, ","), v.State)
+}
+
+func (v vacation) resourceLink() bridge.ResourceLink {
+	name := vacationName
+	if v.Ongoing {
+		name = vacationOngoing
+	}
+
+	links := make([]string, 0, len(v.Groups)+len(v.Schedules)+len(v.Rules))
+	for _, id := range v.Groups {
+		links = append(links, "/groups/"+id)
+	}
+	for _, id := range v.Schedules {
+		links = append(links, "/schedules/"+id)
+	}
+	for _, id := range v.Rules {
+		links = append(links, "/rules/"+id)
+	}
+
+	return bridge.ResourceLink{
+		ID:          v.ID,
+		Name:        name,
+		Description: v.description(),
+		Links:       links,
+	}
+}
+
+// parseVacation reads a vacation stored on bridge
+func parseVacation(link bridge.ResourceLink, location *time.Location) (vacation, error) {
+	parts := strings.SplitN(link.Description, " ", 3)
+	if len(parts) != 3 {
+		return vacation{}, fmt.Errorf("malformed vacation description `%s`", link.Description)
+	}
+
+	output := vacation{
+		ID:      link.ID,
+		State:   parts[2],
+		Ongoing: link.Name == vacationOngoing,
+	}
+
+	period := strings.Split(parts[0], "/")
+	if len(period) != 2 {
+		return vacation{}, fmt.Errorf("malformed vacation period `%s`", parts[0])
+	}
+
+	var err error
+	if output.Start, err = time.ParseInLocation(dayFormat, period[0], location); err != nil {
+		return vacation{}, fmt.Errorf("malformed vacation start: %s", err)
+	}
+	if output.End, err = time.ParseInLocation(dayFormat, period[1], location); err != nil {
+		return vacation{}, fmt.Errorf("malformed vacation end: %s", err)
+	}
+
+	for _, window := range strings.Split(parts[1], ",") {
+		bounds := strings.Split(window, "-")
+		if len(bounds) != 2 {
+			return vacation{}, fmt.Errorf("malformed vacation window `%s`", window)
+		}
+
+		output.Windows = append(output.Windows, vacationWindow{Start: bounds[0], End: bounds[1]})
+	}
+
+	for _, link := range link.Links {
+		parts := strings.Split(strings.Trim(link, "/"), "/")
+		if len(parts) != 2 {
+			continue
+		}
+
+		switch parts[0] {
+		case "groups":
+			output.Groups = append(output.Groups, parts[1])
+		case "schedules":
+			output.Schedules = append(output.Schedules, parts[1])
+		case "rules":
+			output.Rules = append(output.Rules, parts[1])
+		}
+	}
+
+	return output, nil
+}
+
+func midnight(now time.Time) time.Time {
+	year, month, day := now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+// switchTimes picks when lights are switched on and off inside the window. Same day, group and window always give the same times
+func (v vacation) switchTimes(day time.Time, groupID string, index int) (time.Time, time.Time) {
+	start, end, _ := v.Windows[index].bounds()
+
+	seed := fnv.New64a()
+	_, _ = fmt.Fprintf(seed, "%s/%s/%d", day.Format(dayFormat), groupID, index)
+	random := rand.New(rand.NewSource(int64(seed.Sum64())))
+
+	on := start + time.Duration(random.Int63n(int64((end-start)/2)))
+	off := on + minimumLighting + time.Duration(random.Int63n(int64(end-on-minimumLighting)+1))
+
+	return day.Add(on).Truncate(time.Minute), day.Add(off).Truncate(time.Minute)
+}
+
+func vacationScheduleName(groupID string, day time.Time, index int, on bool) string {
+	action := "off"
+	if on {
+		action = "on"
+	}
+
+	return fmt.Sprintf("%s %s %s #%d %s", vacationName, groupID, day.Format("01-02"), index+1, action)
+}
+
+func isVacationSchedule(schedule bridge.Schedule) bool {
+	return schedule.Description == vacationTag
+}
+
+// vacationSchedules lists switches programmed for the vacation, by time. Caller must hold the read lock
+func (a *app) vacationSchedules() []bridge.Schedule {
+	var output []bridge.Schedule
+	for _, schedule := range a.schedules {
+		if isVacationSchedule(schedule) {
+			output = append(output, schedule)
+		}
+	}
+
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].Localtime < output[j].Localtime
+	})
+
+	return output
+}
+
+// regularSchedules lists schedules except vacation's ones. Caller must hold the read lock
+func (a *app) regularSchedules() map[string]bridge.Schedule {
+	output := make(map[string]bridge.Schedule, len(a.schedules))
+	for id, schedule := range a.schedules {
+		if !isVacationSchedule(schedule) {
+			output[id] = schedule
+		}
+	}
+
+	return output
+}
+
+func (a *app) getVacation() *vacation {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.vacation
+}
+
+func (a *app) setVacation(value *vacation) {
+	a.mutex.Lock()
+	a.vacation = value
+	a.mutex.Unlock()
+}
+
+// loadVacation retrieves the vacation stored on bridge, if any
+func (a *app) loadVacation(ctx context.Context) error {
+	links, err := a.client.ListResourceLinks(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list resource links: %s", err)
+	}
+
+	for _, link := range links {
+		if link.Owner != a.bridgeUsername || link.ClassID != vacationClassID || !strings.HasPrefix(link.Name, vacationName) {
+			continue
+		}
+
+		output, err := parseVacation(link, a.now().Location())
+		if err != nil {
+			return err
+		}
+
+		a.setVacation(&output)
+		break
+	}
+
+	return nil
+}
+
+func (a *app) startVacation(ctx context.Context, request vacationRequest) (vacation, error) {
+	a.vacationMutex.Lock()
+	defer a.vacationMutex.Unlock()
+
+	if a.getVacation() != nil {
+		return vacation{}, model.WrapInvalid(errors.New("vacation is already set, stop it first"))
+	}
+
+	a.mutex.RLock()
+	output, err := parseVacationRequest(request, a.now(), a.groups, a.states)
+	a.mutex.RUnlock()
+
+	if err != nil {
+		return vacation{}, err
+	}
+
+	link := output.resourceLink()
+	link.Type = "Link"
+	link.ClassID = vacationClassID
+
+	if err := a.client.CreateResourceLink(ctx, &link); err != nil {
+		return vacation{}, wrapBridgeError(err)
+	}
+
+	output.ID = link.ID
+	a.setVacation(&output)
+
+	if err := a.programVacation(ctx, a.now()); err != nil {
+		return output, wrapBridgeError(err)
+	}
+
+	return *a.getVacation(), nil
+}
+
+func (a *app) stopVacation(ctx context.Context) error {
+	a.vacationMutex.Lock()
+	defer a.vacationMutex.Unlock()
+
+	if a.getVacation() == nil {
+		return model.WrapNotFound(errors.New("no vacation set"))
+	}
+
+	return wrapBridgeError(a.endVacation(ctx))
+}
+
+// runVacation programs the switches of the coming days and ends the vacation on return
+func (a *app) runVacation(ctx context.Context) error {
+	a.vacationMutex.Lock()
+	defer a.vacationMutex.Unlock()
+
+	return a.programVacation(ctx, a.now())
+}
+
+func (a *app) programVacation(ctx context.Context, now time.Time) error {
+	current := a.getVacation()
+	if current == nil {
+		return nil
+	}
+
+	today := midnight(now)
+
+	if !today.Before(current.End) {
+		logger.Info("Back from vacation, restoring schedules and rules")
+		return a.endVacation(ctx)
+	}
+
+	if !today.Before(current.Start) && !current.Ongoing {
+		if err := a.suspendAutomations(ctx, *current); err != nil {
+			return err
+		}
+	}
+
+	a.mutex.RLock()
+	existing := make(map[string]bool)
+	for _, schedule := range a.vacationSchedules() {
+		existing[schedule.Name] = true
+	}
+	a.mutex.RUnlock()
+
+	for _, day := range []time.Time{today, today.AddDate(0, 0, 1)} {
+		if day.Before(current.Start) || !day.Before(current.End) {
+			continue
+		}
+
+		for _, groupID := range current.Groups {
+			for index := range current.Windows {
+				on, off := current.switchTimes(day, groupID, index)
+
+				for _, item := range []vacationSwitch{{current.State, on}, {"off", off}} {
+					name := vacationScheduleName(groupID, day, index, item.State != "off")
+					if existing[name] || !item.Date.After(now) {
+						continue
+					}
+
+					if err := a.createVacationSchedule(ctx, name, groupID, item.State, item.Date, now); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return a.syncSchedules()
+}
+
+func (a *app) createVacationSchedule(ctx context.Context, name, groupID, state string, date, now time.Time) error {
+	schedule, err := a.scheduleFromRequest(scheduleRequest{
+		Name:  name,
+		Group: groupID,
+		State: state,
+		Kind:  bridge.KindAbsolute,
+		Time:  date.Format(dateFormat),
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	schedule.Description = vacationTag
+
+	if err := a.client.CreateSchedule(ctx, &schedule); err != nil {
+		return fmt.Errorf("unable to create vacation schedule `%s`: %s", name, err)
+	}
+
+	return nil
+}
+
+// suspendAutomations disables enabled schedules of the configuration file and rules owned by the app, recording them for being enabled back on return
+func (a *app) suspendAutomations(ctx context.Context, current vacation) error {
+	rules, err := a.client.ListRules(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list rules: %s", err)
+	}
+
+	a.mutex.RLock()
+	for id, schedule := range a.schedules {
+		if schedule.Status == "enabled" && isManagedSchedule(schedule) {
+			current.Schedules = append(current.Schedules, id)
+		}
+	}
+	a.mutex.RUnlock()
+
+	for id, rule := range rules {
+		if rule.Status == "enabled" && rule.Owner == a.bridgeUsername {
+			current.Rules = append(current.Rules, id)
+		}
+	}
+
+	sort.Strings(current.Schedules)
+	sort.Strings(current.Rules)
+	current.Ongoing = true
+
+	link := current.resourceLink()
+	if len(link.Links) > vacationMaxLinks {
+		return fmt.Errorf("too many schedules and rules to suspend: %d links, bridge allows %d", len(link.Links), vacationMaxLinks)
+	}
+
+	for _, id := range current.Schedules {
+		if err := a.client.UpdateSchedule(ctx, bridge.Schedule{ID: id, APISchedule: bridge.APISchedule{Status: "disabled"}}); err != nil {
+			return fmt.Errorf("unable to disable schedule `%s`: %s", id, err)
+		}
+	}
+
+	for _, id := range current.Rules {
+		if err := a.client.UpdateRule(ctx, bridge.Rule{ID: id, Status: "disabled"}); err != nil {
+			return fmt.Errorf("unable to disable rule `%s`: %s", id, err)
+		}
+	}
+
+	if err := a.client.UpdateResourceLink(ctx, link); err != nil {
+		return fmt.Errorf("unable to save vacation: %s", err)
+	}
+
+	a.setVacation(&current)
+
+	return nil
+}
+
+// endVacation removes programmed switches then enables back suspended schedules and rules, those deleted meanwhile are ignored
+func (a *app) endVacation(ctx context.Context) error {
+	current := a.getVacation()
+
+	a.mutex.RLock()
+	switches := a.vacationSchedules()
+	a.mutex.RUnlock()
+
+	for _, schedule := range switches {
+		if err := a.client.DeleteSchedule(ctx, schedule.ID); err != nil && !errors.Is(err, bridge.ErrNotAvailable) {
+			return fmt.Errorf("unable to delete vacation schedule `%s`: %s", schedule.Name, err)
+		}
+	}
+
+	for _, id := range current.Schedules {
+		if err := a.client.UpdateSchedule(ctx, bridge.Schedule{ID: id, APISchedule: bridge.APISchedule{Status: "enabled"}}); err != nil && !errors.Is(err, bridge.ErrNotAvailable) {
+			return fmt.Errorf("unable to enable schedule `%s`: %s", id, err)
+		}
+	}
+
+	for _, id := range current.Rules {
+		if err := a.client.UpdateRule(ctx, bridge.Rule{ID: id, Status: "enabled"}); err != nil && !errors.Is(err, bridge.ErrNotAvailable) {
+			return fmt.Errorf("unable to enable rule `%s`: %s", id, err)
+		}
+	}
+
+	if err := a.client.DeleteResourceLink(ctx, current.ID); err != nil && !errors.Is(err, bridge.ErrNotAvailable) {
+		return fmt.Errorf("unable to delete vacation: %s", err)
+	}
+
+	a.setVacation(nil)
+
+	return a.syncSchedules()
+}
@@ -0,0 +1,197 @@
+package hue
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/hue/pkg/bridge"
+	"github.com/ViBiOh/hue/pkg/fakebridge"
+)
+
+func TestParseVacation(t *testing.T) {
+	type args struct {
+		link bridge.ResourceLink
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      vacation
+		wantErr   bool
+	}{
+		{
+			"ongoing",
+			args{
+				link: bridge.ResourceLink{
+					ID:          "1",
+					Name:        vacationOngoing,
+					Description: "2026-10-18/2026-10-25 18:30-20:00,21:00-23:30 warm white",
+					Links:       []string{"/groups/1", "/groups/2", "/schedules/3", "/rules/4"},
+				},
+			},
+			vacation{
+				Start:     time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+				End:       time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC),
+				ID:        "1",
+				State:     "warm white",
+				Groups:    []string{"1", "2"},
+				Windows:   []vacationWindow{{Start: "18:30", End: "20:00"}, {Start: "21:00", End: "23:30"}},
+				Schedules: []string{"3"},
+				Rules:     []string{"4"},
+				Ongoing:   true,
+			},
+			false,
+		},
+		{
+			"malformed",
+			args{
+				link: bridge.ResourceLink{
+					Name:        vacationName,
+					Description: "2026-10-18 half",
+				},
+			},
+			vacation{},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			got, err := parseVacation(tc.args.link, time.UTC)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("parseVacation() error = %v, wantErr %t", err, tc.wantErr)
+			} else if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseVacation() = %+v, want %+v", got, tc.want)
+			} else if err == nil && !reflect.DeepEqual(got.resourceLink(), tc.args.link) {
+				t.Errorf("resourceLink() = %+v, want %+v", got.resourceLink(), tc.args.link)
+			}
+		})
+	}
+}
+
+func TestSwitchTimes(t *testing.T) {
+	instance := vacation{
+		Windows: []vacationWindow{{Start: "18:30", End: "23:30"}},
+	}
+
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	windowStart := day.Add(18*time.Hour + 30*time.Minute)
+	windowEnd := day.Add(23*time.Hour + 30*time.Minute)
+
+	on, off := instance.switchTimes(day, "1", 0)
+
+	if on.Before(windowStart) || off.After(windowEnd) || off.Sub(on) < minimumLighting {
+		t.Errorf("switchTimes() = %s - %s, want inside window for at least %s", on, off, minimumLighting)
+	}
+
+	if sameOn, sameOff := instance.switchTimes(day, "1", 0); !sameOn.Equal(on) || !sameOff.Equal(off) {
+		t.Errorf("switchTimes() = %s - %s, want same as first call %s - %s", sameOn, sameOff, on, off)
+	}
+}
+
+func TestParseVacationRequest(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	groups := map[string]Group{
+		"1":           {Group: bridge.Group{Name: "Living room"}},
+		"12345678901": {Group: bridge.Group{Name: "Entity"}},
+	}
+
+	type args struct {
+		request vacationRequest
+	}
+
+	var cases = []struct {
+		intention string
+		args      args
+		want      string
+	}{
+		{
+			"valid",
+			args{request: vacationRequest{Start: "2026-10-18", End: "2026-10-25", Groups: []string{"1"}}},
+			"",
+		},
+		{
+			"invalid",
+			args{request: vacationRequest{Start: "2026-10-17", End: "2026-10-17", State: "off", Groups: []string{"2"}}},
+			"start `2026-10-17` is in the past, end `2026-10-17` must be after start, unknown state 'off' for switching on, unknown group '2'",
+		},
+		{
+			"name too long",
+			args{request: vacationRequest{Start: "2026-10-18", End: "2026-10-25", Groups: []string{"12345678901"}}},
+			"group '12345678901' gives schedule names longer than 32 characters, e.g. `Vacation 12345678901 10-18 #1 off`",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			_, err := parseVacationRequest(tc.args.request, now, groups, States)
+
+			got := ""
+			if err != nil {
+				got = strings.TrimSuffix(err.Error(), ": invalid")
+			}
+
+			if got != tc.want {
+				t.Errorf("parseVacationRequest() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStartVacation(t *testing.T) {
+	fake, server := fakebridge.NewServer("secret")
+	defer server.Close()
+
+	instance := &app{
+		bridgeIP:       fakebridge.Address(server),
+		bridgeUsername: "secret",
+		states:         States,
+		v1:             true,
+	}
+
+	ctx := context.Background()
+
+	if err := instance.connect(); err != nil {
+		t.Fatalf("connect() error = %s", err)
+	}
+
+	config := configHue{
+		Schedules: []ScheduleConfig{{Name: "Wake Up", Localtime: "W124/T07:55:00", Group: "2", State: "long_on"}},
+		Switches:  []configSwitch{{ID: "10", Buttons: []configSwitchButton{{Button: "on", Event: "short_release", States: []string{"on"}, Groups: []string{"2"}}}}},
+	}
+
+	if err := instance.reconcile(ctx, config); err != nil {
+		t.Fatalf("reconcile() error = %s", err)
+	}
+
+	if err := instance.syncGroups(); err != nil {
+		t.Fatalf("syncGroups() error = %s", err)
+	}
+
+	if err := instance.syncSchedules(); err != nil {
+		t.Fatalf("syncSchedules() error = %s", err)
+	}
+
+	today := instance.now().Format(dayFormat)
+	output, err := instance.startVacation(ctx, vacationRequest{Start: today, End: instance.now().AddDate(0, 0, 7).Format(dayFormat), Groups: []string{"1"}})
+	if err != nil {
+		t.Fatalf("startVacation() error = %s", err)
+	}
+
+	if got := output.Start.Location().String(); got != "Europe/Paris" {
+		t.Errorf("startVacation() start in %s, want bridge timezone", got)
+	}
+
+	if len(output.Schedules) != 1 || len(output.Rules) != 1 {
+		t.Errorf("startVacation() suspended %d schedules and %d rules, want 1 and 1", len(output.Schedules), len(output.Rules))
+	}
+
+	for _, resource := range []string{"schedules", "rules"} {
+		if item, _ := fake.Get(resource, "1"); item["status"] != "enabled" {
+			t.Errorf("startVacation() changed %s of the official app, status = %v", resource, item["status"])
+		}
+	}
+}
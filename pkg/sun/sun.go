@@ -0,0 +1,69 @@
+package sun
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	j2000         = 2451545.0
+	unixEpochJD   = 2440587.5
+	secondsPerDay = 86400
+	obliquity     = 23.4397
+	horizon       = -0.833
+)
+
+var (
+	// ErrNoEvent occurs when the sun doesn't rise or set on that day, during polar night or midnight sun
+	ErrNoEvent = errors.New("sun doesn't rise or set on this day")
+)
+
+// Sunrise computes the sunrise of the given day, at given coordinates in degrees, in the location of the date
+func Sunrise(date time.Time, latitude, longitude float64) (time.Time, error) {
+	sunrise, _, err := times(date, latitude, longitude)
+	return sunrise, err
+}
+
+// Sunset computes the sunset of the given day, at given coordinates in degrees, in the location of the date
+func Sunset(date time.Time, latitude, longitude float64) (time.Time, error) {
+	_, sunset, err := times(date, latitude, longitude)
+	return sunset, err
+}
+
+// times implements the sunrise equation, accurate to the minute
+func times(date time.Time, latitude, longitude float64) (time.Time, time.Time, error) {
+	year, month, day := date.Date()
+	noon := time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+
+	n := math.Round(float64(noon.Unix())/secondsPerDay + unixEpochJD - j2000 + 0.0008)
+	meanSolarTime := n - longitude/360
+
+	anomaly := math.Mod(357.5291+0.98560028*meanSolarTime, 360)
+	center := 1.9148*sin(anomaly) + 0.0200*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	eclipticLongitude := math.Mod(anomaly+center+180+102.9372, 360)
+
+	transit := j2000 + meanSolarTime + 0.0053*sin(anomaly) - 0.0069*sin(2*eclipticLongitude)
+	declination := math.Asin(sin(eclipticLongitude) * sin(obliquity))
+
+	cosHourAngle := (sin(horizon) - sin(latitude)*math.Sin(declination)) / (cos(latitude) * math.Cos(declination))
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, ErrNoEvent
+	}
+
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+
+	return fromJulian(transit-hourAngle/360, date.Location()), fromJulian(transit+hourAngle/360, date.Location()), nil
+}
+
+func fromJulian(julian float64, location *time.Location) time.Time {
+	return time.Unix(int64(math.Round((julian-unixEpochJD)*secondsPerDay)), 0).In(location)
+}
+
+func sin(degrees float64) float64 {
+	return math.Sin(degrees * math.Pi / 180)
+}
+
+func cos(degrees float64) float64 {
+	return math.Cos(degrees * math.Pi / 180)
+}
@@ -0,0 +1,85 @@
+package sun
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimes(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatalf("unable to load location: %s", err)
+	}
+
+	type args struct {
+		date      time.Time
+		latitude  float64
+		longitude float64
+	}
+
+	var cases = []struct {
+		intention   string
+		args        args
+		wantSunrise string
+		wantSunset  string
+		wantErr     error
+	}{
+		{
+			"summer solstice in Paris",
+			args{
+				date:      time.Date(2021, 6, 21, 0, 0, 0, 0, paris),
+				latitude:  48.8566,
+				longitude: 2.3522,
+			},
+			"05:46",
+			"21:57",
+			nil,
+		},
+		{
+			"winter solstice in Paris",
+			args{
+				date:      time.Date(2021, 12, 21, 15, 0, 0, 0, paris),
+				latitude:  48.8566,
+				longitude: 2.3522,
+			},
+			"08:41",
+			"16:56",
+			nil,
+		},
+		{
+			"polar night",
+			args{
+				date:      time.Date(2021, 12, 21, 0, 0, 0, 0, time.UTC),
+				latitude:  69.6492,
+				longitude: 18.9553,
+			},
+			"",
+			"",
+			ErrNoEvent,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.intention, func(t *testing.T) {
+			sunrise, sunset, err := times(tc.args.date, tc.args.latitude, tc.args.longitude)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("times() error = %v, want %v", err, tc.wantErr)
+				return
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got := sunrise.Format("15:04"); got != tc.wantSunrise {
+				t.Errorf("times() sunrise = %s, want %s", got, tc.wantSunrise)
+			}
+
+			if got := sunset.Format("15:04"); got != tc.wantSunset {
+				t.Errorf("times() sunset = %s, want %s", got, tc.wantSunset)
+			}
+		})
+	}
+}